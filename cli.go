@@ -5,20 +5,31 @@ import (
 	"os"
 	"path/filepath"
 
-	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 
 	"github.com/rumorshub/ioc/configwise"
 	"github.com/rumorshub/ioc/logwise"
 )
 
-const envDotenv = "DOTENV_PATH"
+const (
+	envDotenv  = "DOTENV_PATH"
+	envProfile = "PROFILE"
+)
 
 func NewCommand(args []string, short, envPrefix, version string) *cobra.Command {
 	var (
-		cfgFile  string
+		cfgFiles []string
 		dotenv   string
+		profile  string
+		strict   bool
+		optional bool
+		tmpl     bool
+		validate bool
+		schema   string
 		override []string
+
+		cfg configwise.Configurer
 	)
 
 	cmd := &cobra.Command{
@@ -30,26 +41,60 @@ func NewCommand(args []string, short, envPrefix, version string) *cobra.Command
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
 			cmd.Version = version
 
-			if absPath, err := filepath.Abs(cfgFile); err == nil {
-				cfgFile = absPath
-			}
+			cfgFiles = uniquePaths(cfgFiles)
 
 			if v, ok := os.LookupEnv(envDotenv); ok {
 				dotenv = v
 			}
 
-			if _, err := os.Stat(dotenv); err == nil {
-				if err = godotenv.Load(dotenv); err != nil {
+			var warnings []string
+
+			opts := []configwise.Option{
+				configwise.WithWarnings(func(msg string) {
+					warnings = append(warnings, msg)
+				}),
+				configwise.WithPaths(cfgFiles...),
+				configwise.WithPrefix(envPrefix),
+				configwise.WithFlags(override),
+			}
+
+			// the profile variable might come from the dotenv file, so the configurer reads it
+			if cmd.Flags().Changed("profile") {
+				opts = append(opts, configwise.WithProfile(profile))
+			} else {
+				opts = append(opts, configwise.WithProfileEnv(envPrefix+"_"+envProfile))
+			}
+
+			if strict {
+				opts = append(opts, configwise.WithStrictEnv())
+			}
+
+			if optional {
+				opts = append(opts, configwise.WithOptionalFile())
+			}
+
+			if tmpl {
+				opts = append(opts, configwise.WithTemplate())
+			}
+
+			switch {
+			case schema != "":
+				opts = append(opts, configwise.WithSchemaFile(schema))
+			case validate:
+				s, err := configwise.GenerateSchema()
+				if err != nil {
 					return err
 				}
+				opts = append(opts, configwise.WithSchema(s))
 			}
 
-			cfg, err := configwise.NewConfigurer(
-				version,
-				configwise.WithPath(cfgFile),
-				configwise.WithPrefix(envPrefix),
-				configwise.WithFlags(override),
-			)
+			if _, err := os.Stat(dotenv); err == nil {
+				opts = append(opts, configwise.WithDotenv(dotenv))
+			}
+
+			var err error
+
+			cfg, err = configwise.NewConfigurer(version, opts...)
 			if err != nil {
 				return err
 			}
@@ -59,6 +104,16 @@ func NewCommand(args []string, short, envPrefix, version string) *cobra.Command
 				return err
 			}
 
+			cfgLog := log.NamedLogger(configwise.PluginName)
+
+			if len(cfg.Files()) == 0 {
+				cfgLog.Info("no config file loaded, using environment, overrides and defaults")
+			}
+
+			for _, w := range warnings {
+				cfgLog.Warn(w)
+			}
+
 			cont := NewContainer(cfg, log)
 
 			cmd.SetContext(WithContainer(cmd.Context(), cont))
@@ -68,11 +123,44 @@ func NewCommand(args []string, short, envPrefix, version string) *cobra.Command
 	}
 
 	f := cmd.PersistentFlags()
-	f.StringVarP(&cfgFile, "config", "c", "config.yaml", "config file")
+	f.StringArrayVarP(&cfgFiles, "config", "c", []string{"config.yaml"}, "config file or directory, repeat to merge several (later wins)")
 	f.StringVar(&dotenv, "dotenv", ".env", fmt.Sprintf("dotenv file [$%s]", envDotenv))
+	f.StringVar(&profile, "profile", "", fmt.Sprintf("environment profile, merges config.<profile>.yaml over config.yaml [$%s_%s]", envPrefix, envProfile))
+	f.BoolVar(&optional, "config-optional", false, "start without config file if it does not exist")
+	f.BoolVar(&tmpl, "config-template", false, "render config files with text/template before parsing")
+	f.BoolVar(&validate, "config-validate", false, "validate config against the schema of the registered plugins (see config schema)")
+	f.StringVar(&schema, "config-schema", "", "validate config against the JSON Schema file")
+	f.BoolVar(&strict, "strict-env", false, "fail on references to undefined environment variables")
 	f.StringArrayVarP(&override, "override", "o", nil, "override config value (dot.notation=value)")
 
 	_ = f.Parse(args[1:])
 
+	cmd.AddCommand(newConfigCommand(
+		func() configwise.Configurer { return cfg },
+		// the config is not loaded for migrate, so the files are looked up the same way
+		func(cmd *cobra.Command) ([]string, error) {
+			p := profile
+			if !cmd.Flags().Changed("profile") {
+				p = os.Getenv(envPrefix + "_" + envProfile)
+			}
+			return configwise.FindFiles(p, uniquePaths(cfgFiles)...)
+		},
+	))
+
 	return cmd
 }
+
+// uniquePaths makes the paths absolute and drops the repeated ones, flags are parsed
+// twice (see NewCommand), so repeated values are duplicated.
+func uniquePaths(paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			path = absPath
+		}
+		if !slices.Contains(out, path) {
+			out = append(out, path)
+		}
+	}
+	return out
+}
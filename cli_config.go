@@ -0,0 +1,302 @@
+package ioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rumorshub/ioc/configwise"
+)
+
+const redacted = "******"
+
+var secretKey = regexp.MustCompile(`(?i)(pass(word)?|secret|token|api_?key|private_?key|credentials?|dsn)$`)
+
+// sourceFunc returns the source of the value of the dotted key.
+type sourceFunc func(key string) string
+
+// filesFunc returns the config files the command line points to.
+type filesFunc func(cmd *cobra.Command) ([]string, error)
+
+// skipConfig replaces the root hook for the commands which work without loading the config,
+// an invalid or outdated config should not stop them.
+func skipConfig(*cobra.Command, []string) error {
+	return nil
+}
+
+func newConfigCommand(cfg func() configwise.Configurer, files filesFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Config utilities",
+	}
+
+	cmd.AddCommand(
+		newConfigPrintCommand(cfg),
+		newConfigMigrateCommand(files),
+		newConfigSchemaCommand(),
+	)
+
+	return cmd
+}
+
+func newConfigPrintCommand(cfg func() configwise.Configurer) *cobra.Command {
+	var (
+		format      string
+		sources     bool
+		showSecrets bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "print [section]",
+		Short: "Print the effective config",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				prefix string
+				tree   interface{} = cfg().AllSettings()
+			)
+
+			if len(args) == 1 {
+				prefix = strings.ToLower(args[0])
+				if !cfg().Has(prefix) {
+					return fmt.Errorf("config section `%s` not found", args[0])
+				}
+				tree = cfg().Get(prefix)
+			}
+
+			if !showSecrets {
+				tree = redact(prefix, tree)
+			}
+
+			// JSON sources change the document shape, so they are opt-in
+			if strings.EqualFold(format, "json") && !cmd.Flags().Changed("sources") {
+				sources = false
+			}
+
+			var source sourceFunc
+			if sources {
+				source = func(key string) string {
+					if o, ok := cfg().Origin(key); ok {
+						return o.String()
+					}
+					return ""
+				}
+			}
+
+			switch strings.ToLower(format) {
+			case "yaml", "yml":
+				return printYAML(cmd.OutOrStdout(), prefix, tree, source)
+			case "json":
+				return printJSON(cmd.OutOrStdout(), prefix, tree, source)
+			default:
+				return fmt.Errorf("unknown format `%s`, use yaml or json", format)
+			}
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&format, "format", "f", "yaml", "output format (yaml, json)")
+	f.BoolVar(&sources, "sources", true, `annotate values with their source, JSON is wrapped as {"config", "sources"} only when set explicitly`)
+	f.BoolVar(&showSecrets, "show-secrets", false, "do not redact secret values")
+
+	return cmd
+}
+
+func newConfigMigrateCommand(configFiles filesFunc) *cobra.Command {
+	var write bool
+
+	cmd := &cobra.Command{
+		Use:               "migrate [file...]",
+		Short:             fmt.Sprintf("Upgrade config files to version %d", configwise.LatestVersion()),
+		PersistentPreRunE: skipConfig,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			files := args
+			if len(files) == 0 {
+				var err error
+				if files, err = configFiles(cmd); err != nil {
+					return err
+				}
+			}
+
+			for _, file := range files {
+				data, warnings, err := configwise.MigrateFile(file)
+				if err != nil {
+					return fmt.Errorf("%s: %w", file, err)
+				}
+
+				for _, w := range warnings {
+					cmd.PrintErrf("%s: %s\n", file, w)
+				}
+
+				if data == nil {
+					cmd.PrintErrf("%s: already at version %d\n", file, configwise.LatestVersion())
+					continue
+				}
+
+				if write {
+					info, err := os.Stat(file)
+					if err != nil {
+						return err
+					}
+					if err = os.WriteFile(file, data, info.Mode()); err != nil {
+						return err
+					}
+					continue
+				}
+
+				if len(files) > 1 {
+					cmd.Printf("# %s\n", file)
+				}
+				cmd.Print(string(data))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&write, "write", "w", false, "write the result to the files instead of stdout")
+
+	return cmd
+}
+
+func newConfigSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "schema",
+		Short:             "Print the JSON Schema of the config file",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: skipConfig,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			schema, err := configwise.GenerateSchema()
+			if err != nil {
+				return err
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(schema)
+		},
+	}
+}
+
+func redact(key string, value interface{}) interface{} {
+	if secretKey.MatchString(lastKey(key)) {
+		return redacted
+	}
+
+	if m, ok := value.(map[string]interface{}); ok {
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[k] = redact(joinKey(key, k), v)
+		}
+		return out
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		out := make([]interface{}, len(list))
+		for i, v := range list {
+			out[i] = redact(joinKey(key, strconv.Itoa(i)), v)
+		}
+		return out
+	}
+
+	return value
+}
+
+func printYAML(w io.Writer, prefix string, tree interface{}, source sourceFunc) error {
+	node, err := yamlNode(prefix, tree, source)
+	if err != nil {
+		return err
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2) //nolint:gomnd
+	if err = enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func yamlNode(key string, value interface{}, source sourceFunc) (*yaml.Node, error) {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		node := &yaml.Node{}
+		return node, node.Encode(value)
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for _, k := range sortedKeys(m) {
+		full := joinKey(key, k)
+
+		val, err := yamlNode(full, m[k], source)
+		if err != nil {
+			return nil, err
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: k}
+		if _, isMap := m[k].(map[string]interface{}); !isMap && source != nil {
+			keyNode.LineComment = source(full)
+		}
+
+		node.Content = append(node.Content, keyNode, val)
+	}
+	return node, nil
+}
+
+func printJSON(w io.Writer, prefix string, tree interface{}, source sourceFunc) error {
+	var out interface{} = tree
+
+	if source != nil {
+		sources := make(map[string]string)
+		collectSources(prefix, tree, source, sources)
+
+		out = map[string]interface{}{
+			"config":  tree,
+			"sources": sources,
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func collectSources(key string, value interface{}, source sourceFunc, out map[string]string) {
+	if m, ok := value.(map[string]interface{}); ok {
+		for k, v := range m {
+			collectSources(joinKey(key, k), v, source, out)
+		}
+		return
+	}
+	out[key] = source(key)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func lastKey(key string) string {
+	if i := strings.LastIndexByte(key, '.'); i != -1 {
+		return key[i+1:]
+	}
+	return key
+}
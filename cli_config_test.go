@@ -0,0 +1,200 @@
+package ioc
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rumorshub/ioc/configwise"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		in   interface{}
+		want interface{}
+	}{
+		{
+			name: "leaf",
+			key:  "db.password",
+			in:   "hunter2",
+			want: redacted,
+		},
+		{
+			name: "plain leaf",
+			key:  "db.host",
+			in:   "localhost",
+			want: "localhost",
+		},
+		{
+			name: "nested map",
+			key:  "",
+			in: map[string]interface{}{
+				"db": map[string]interface{}{"host": "localhost", "password": "hunter2"},
+			},
+			want: map[string]interface{}{
+				"db": map[string]interface{}{"host": "localhost", "password": redacted},
+			},
+		},
+		{
+			name: "maps in list",
+			key:  "db",
+			in: map[string]interface{}{
+				"ups": []interface{}{
+					map[string]interface{}{"host": "a", "password": "hunter2"},
+					map[string]interface{}{"host": "b", "api_key": "k"},
+				},
+			},
+			want: map[string]interface{}{
+				"ups": []interface{}{
+					map[string]interface{}{"host": "a", "password": redacted},
+					map[string]interface{}{"host": "b", "api_key": redacted},
+				},
+			},
+		},
+		{
+			name: "secret key holding list",
+			key:  "http.token",
+			in:   []interface{}{"a", "b"},
+			want: redacted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redact(tt.key, tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redact(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigPrintSources(t *testing.T) {
+	cfg, err := configwise.NewConfigurer("v1",
+		configwise.WithConfigType("yaml"),
+		configwise.WithReadInCfg([]byte("http:\n  port: 80\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		json bool
+		// sources reports whether the output should be annotated
+		sources bool
+	}{
+		{name: "yaml", args: []string{"http"}, sources: true},
+		{name: "yaml without sources", args: []string{"http", "--sources=false"}},
+		{name: "json", args: []string{"http", "-f", "json"}, json: true},
+		{name: "json with sources", args: []string{"http", "-f", "json", "--sources"}, json: true, sources: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			cmd := newConfigPrintCommand(func() configwise.Configurer { return cfg })
+			cmd.SetOut(&out)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.json {
+				if got := strings.Contains(out.String(), "#"); got != tt.sources {
+					t.Errorf("annotated = %v, want %v:\n%s", got, tt.sources, out.String())
+				}
+				return
+			}
+
+			var doc map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if _, got := doc["sources"]; got != tt.sources {
+				t.Errorf("annotated = %v, want %v:\n%s", got, tt.sources, out.String())
+			}
+			if !tt.sources && doc["port"] != float64(80) {
+				t.Errorf("output is not the effective config:\n%s", out.String())
+			}
+		})
+	}
+}
+
+func TestConfigCommandsWithoutLoading(t *testing.T) {
+	dir := t.TempDir()
+
+	// the undefined variable fails the config loading with --strict-env
+	files := map[string]string{
+		"config.yaml":      "http:\n  address: ${IOC_TEST_UNDEFINED}\n",
+		"config.prod.yaml": "http:\n  port: 80\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		// out and errOut are the substrings expected in stdout and stderr
+		out    string
+		errOut []string
+	}{
+		{
+			name: "schema",
+			args: []string{"config", "schema"},
+			out:  `"properties"`,
+		},
+		{
+			name:   "migrate",
+			args:   []string{"config", "migrate", "--profile", "prod"},
+			errOut: []string{"config.yaml: already at version", "config.prod.yaml: already at version"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, errOut bytes.Buffer
+
+			args := append([]string{"app", "--strict-env", "-c", dir}, tt.args...)
+
+			cmd := NewCommand(args, "test", "APP", "v1")
+			cmd.SetArgs(args[1:])
+			cmd.SetOut(&out)
+			cmd.SetErr(&errOut)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(out.String(), tt.out) {
+				t.Errorf("output has no %s:\n%s", tt.out, out.String())
+			}
+			for _, want := range tt.errOut {
+				if !strings.Contains(errOut.String(), want) {
+					t.Errorf("errors have no %s:\n%s", want, errOut.String())
+				}
+			}
+		})
+	}
+
+	// the print command loads the config
+	args := []string{"app", "--strict-env", "-c", dir, "config", "print"}
+	cmd := NewCommand(args, "test", "APP", "v1")
+	cmd.SetArgs(args[1:])
+	cmd.SetOut(&bytes.Buffer{})
+
+	if err := cmd.Execute(); err == nil {
+		t.Error("config print succeeded with the undefined variable")
+	}
+}
@@ -1,40 +1,99 @@
 package ioc
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/rumorshub/ioc/configwise"
 )
 
 type Config struct {
-	GracePeriod time.Duration
-	PrintGraph  bool
+	GracePeriod    time.Duration `mapstructure:"grace_period" doc:"time to wait for plugins to stop"`
+	DrainPeriod    time.Duration `mapstructure:"drain_period" doc:"time between readiness going down and stop"`
+	StartupTimeout time.Duration `mapstructure:"startup_timeout" doc:"deadline of plugins Init and Serve, zero disables it"`
+	PrintGraph     bool          `mapstructure:"print_graph" doc:"print the dependency graph in the DOT format"`
+
+	// LogLevel overrides the level of the endure internal logger, empty keeps the channel level.
+	LogLevel string `mapstructure:"log_level" doc:"level of the endure internal logger" enum:"debug,info,warn,error"`
+	// Profiler starts endure pprof server on 0.0.0.0:6061.
+	Profiler bool `mapstructure:"profiler" doc:"start pprof server on 0.0.0.0:6061"`
+
+	// Signals are the names of the signals which trigger graceful stop.
+	Signals []string `mapstructure:"signals" doc:"signals which trigger graceful stop"`
+	// ForceExit enables exit on repeated stop signal.
+	ForceExit bool `mapstructure:"force_exit" doc:"exit on repeated stop signal"`
+	// ForceExitSignals is the number of repeated signals required to force exit.
+	ForceExitSignals int `mapstructure:"force_exit_signals" doc:"number of repeated signals required to force exit"`
+	// KillTimeout is the deadline after the first stop signal after which
+	// the process exits even if the container is not stopped yet.
+	KillTimeout time.Duration `mapstructure:"kill_timeout" doc:"exit after the first stop signal even if plugins are still stopping"`
+
+	// StrictConfig fails Init on config keys no plugin reads, they are logged otherwise.
+	StrictConfig bool `mapstructure:"strict_config" doc:"fail on config keys no plugin reads"`
+
+	// Plugins holds per plugin settings, the key is the plugin name.
+	Plugins map[string]PluginConfig `mapstructure:"plugins" doc:"per plugin settings, the key is the plugin name"`
 }
 
-// NewConfig creates endure container configuration.
-func NewConfig(cfg configwise.Configurer, key string) (*Config, error) {
-	if !cfg.Has(key) {
-		return &Config{
-			GracePeriod: configwise.DefaultGracefulTimeout,
-			PrintGraph:  false,
-		}, nil
+type PluginConfig struct {
+	// StartupTimeout bounds Init and Serve of the plugin, overrides the global one.
+	StartupTimeout time.Duration `mapstructure:"startup_timeout" doc:"deadline of the plugin Init and Serve"`
+}
+
+// defaultConfig returns the endure defaults, it is registered as the defaults of the section.
+func defaultConfig() Config {
+	return Config{
+		GracePeriod:      configwise.DefaultGracefulTimeout,
+		Signals:          []string{"SIGINT", "SIGTERM"},
+		ForceExit:        true,
+		ForceExitSignals: 1,
 	}
+}
+
+func init() {
+	configwise.RegisterDefaults(endureKey, defaultConfig())
+	configwise.RegisterSchema(endureKey, Config{})
+}
 
-	cfgEndure := struct {
-		GracePeriod time.Duration `mapstructure:"grace_period"`
-		PrintGraph  bool          `mapstructure:"print_graph"`
-	}{}
+// NewConfig creates endure container configuration over the defaults,
+// zero grace period means the default one.
+func NewConfig(cfg configwise.Configurer, key string) (*Config, error) {
+	def := defaultConfig()
+	c := &def
 
-	if err := cfg.UnmarshalKey(key, &cfgEndure); err != nil {
+	// mapstructure appends to a non-empty slice, the configured signals replace the default ones
+	if cfg.Has(key + ".signals") {
+		c.Signals = nil
+	}
+
+	if err := cfg.UnmarshalKey(key, c); err != nil {
 		return nil, err
 	}
 
-	if cfgEndure.GracePeriod == 0 {
-		cfgEndure.GracePeriod = configwise.DefaultGracefulTimeout
+	if c.GracePeriod == 0 {
+		c.GracePeriod = configwise.DefaultGracefulTimeout
+	}
+
+	// signal.Notify without signals relays all of them
+	if len(c.Signals) == 0 {
+		return nil, fmt.Errorf("`%s.signals` should not be empty", key)
 	}
 
-	return &Config{
-		GracePeriod: cfgEndure.GracePeriod,
-		PrintGraph:  cfgEndure.PrintGraph,
-	}, nil
+	if c.ForceExitSignals < 1 {
+		return nil, fmt.Errorf("`%s.force_exit_signals` should be positive, got %d", key, c.ForceExitSignals)
+	}
+
+	if _, err := c.OSSignals(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// PluginStartupTimeout returns the startup timeout for the named plugin.
+func (c *Config) PluginStartupTimeout(name string) time.Duration {
+	if p, ok := c.Plugins[name]; ok && p.StartupTimeout > 0 {
+		return p.StartupTimeout
+	}
+	return c.StartupTimeout
 }
@@ -0,0 +1,136 @@
+package ioc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rumorshub/ioc/configwise"
+)
+
+func TestNewConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		check  func(t *testing.T, c *Config)
+		err    string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c *Config) {
+				if want := []string{"SIGINT", "SIGTERM"}; !reflect.DeepEqual(c.Signals, want) {
+					t.Errorf("Signals = %v, want %v", c.Signals, want)
+				}
+				if c.GracePeriod != configwise.DefaultGracefulTimeout {
+					t.Errorf("GracePeriod = %s, want %s", c.GracePeriod, configwise.DefaultGracefulTimeout)
+				}
+				if !c.ForceExit || c.ForceExitSignals != 1 {
+					t.Errorf("ForceExit = %v/%d, want true/1", c.ForceExit, c.ForceExitSignals)
+				}
+			},
+		},
+		{
+			name:   "custom signals",
+			config: "endure:\n  signals: [hup]\n",
+			check: func(t *testing.T, c *Config) {
+				if want := []string{"hup"}; !reflect.DeepEqual(c.Signals, want) {
+					t.Errorf("Signals = %v, want %v", c.Signals, want)
+				}
+			},
+		},
+		{
+			name:   "zero grace period",
+			config: "endure:\n  grace_period: 0s\n",
+			check: func(t *testing.T, c *Config) {
+				if c.GracePeriod != configwise.DefaultGracefulTimeout {
+					t.Errorf("GracePeriod = %s, want %s", c.GracePeriod, configwise.DefaultGracefulTimeout)
+				}
+			},
+		},
+		{
+			name:   "empty signals",
+			config: "endure:\n  signals: []\n",
+			err:    "`endure.signals` should not be empty",
+		},
+		{
+			name:   "unknown signal",
+			config: "endure:\n  signals: [SIGWINCH]\n",
+			err:    "unknown signal `SIGWINCH`",
+		},
+		{
+			name:   "no force exit signals",
+			config: "endure:\n  force_exit_signals: 0\n",
+			err:    "`endure.force_exit_signals` should be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := configwise.NewConfigurer("v1",
+				configwise.WithConfigType("yaml"),
+				configwise.WithReadInCfg([]byte(tt.config)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c, err := NewConfig(cfg, endureKey)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("NewConfig() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.check(t, c)
+		})
+	}
+}
+
+// emptyConfigurer is a Configurer without the registered defaults.
+type emptyConfigurer struct {
+	configwise.Configurer
+}
+
+func (emptyConfigurer) UnmarshalKey(string, interface{}) error { return nil }
+func (emptyConfigurer) Has(string) bool                        { return false }
+
+func TestNewConfigDefaults(t *testing.T) {
+	c, err := NewConfig(emptyConfigurer{}, endureKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := defaultConfig(); !reflect.DeepEqual(*c, want) {
+		t.Errorf("NewConfig() = %+v, want %+v", *c, want)
+	}
+}
+
+func TestParseSignal(t *testing.T) {
+	for _, name := range []string{"SIGTERM", "sigterm", "term", " TERM "} {
+		if _, err := ParseSignal(name); err != nil {
+			t.Errorf("ParseSignal(%q) error = %v", name, err)
+		}
+	}
+
+	if _, err := ParseSignal("SIGWINCH"); err == nil {
+		t.Error("ParseSignal(SIGWINCH) succeeded")
+	}
+}
+
+// waitFor polls the condition until it's true or the timeout is over.
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
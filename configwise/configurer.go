@@ -28,9 +28,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
@@ -46,6 +50,8 @@ const (
 
 const DefaultGracefulTimeout = 30 * time.Second
 
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
 type Configurer interface {
 	// UnmarshalKey takes a single key and unmarshal it into a Struct.
 	UnmarshalKey(name string, out interface{}) error
@@ -63,10 +69,41 @@ type Configurer interface {
 	// Has checks if config section exists.
 	Has(name string) bool
 
+	// AllSettings returns the effective config as nested map.
+	AllSettings() map[string]interface{}
+
+	// Origin returns where the value of the key came from.
+	Origin(name string) (Origin, bool)
+
+	// Sub returns a view of the config rooted at the key,
+	// all the keys passed to the view are relative to it.
+	Sub(key string) Configurer
+
+	// Snapshot returns a consistent read-only view of the current config,
+	// it is not affected by the following writes.
+	Snapshot() Configurer
+
+	// Reload reads the config again with the same options.
+	Reload() error
+
+	// Watch subscribes to the changes of the value at the prefix (empty prefix is
+	// the whole config) made by Overwrite or Reload, fn receives the old and the new
+	// value and must not write to the config. The returned function cancels the subscription.
+	Watch(prefix string, fn func(old, new interface{})) (cancel func())
+
 	GracefulTimeout() time.Duration
 
 	SetGracefulTimeout(timeout time.Duration) Configurer
 
+	// Files returns the config files which were read, in the merge order.
+	Files() []string
+
+	// Profile returns the active environment profile, empty if none.
+	Profile() string
+
+	// UnusedKeys returns the config keys nobody read and stops tracking the reads.
+	UnusedKeys() []UnusedKey
+
 	// Version returns current version
 	Version() string
 }
@@ -75,22 +112,65 @@ type Option func(*configurer)
 
 type configurer struct {
 	viper     *viper.Viper
-	path      string
+	paths     []string
+	listMerge ListMerge
 	prefix    string
-	tp        string
-	readInCfg []byte
+	profile   string
+	// profileEnv is the variable holding the profile
+	profileEnv string
+	tp         string
+	readInCfg  []byte
+	resolvers  map[string]Resolver
+	assigned   map[string]string
+	strictEnv  bool
+	template   bool
+	schema     *Schema
+	schemaFile string
+	// rendered holds the config templates after rendering
+	rendered map[string][]byte
+	warn     func(msg string)
+	missing  []EnvRef
 	// user defined Flags in the form of <option>.<key> = <value>
 	// which overwrites initial config key
 	flags []string
 
+	// dotenv file path and the variables loaded from it
+	dotenvPath string
+	dotenv     map[string]string
+	origins    map[string]Origin
+	// files maps the keys to the config files they came from
+	files map[string]string
+	// loaded config files
+	loaded      []string
+	optional    bool
+	defaults    []sectionDefaults
+	defaultKeys map[string]struct{}
+	// envKeys maps the keys materialised from the environment to the variables names
+	envKeys map[string]string
+
+	// options are kept to reload the config
+	options []Option
+	usage   *usage
+
+	// mu serialises the writes, readers load the current snapshot
+	mu   sync.Mutex
+	snap atomic.Pointer[snapshot]
+
+	watchMu  sync.Mutex
+	watchers map[uint64]*watcher
+	watchID  uint64
+
 	// Timeout ...
-	timeout time.Duration
+	timeout atomic.Int64
 	version string
 }
 
 func WithPath(path string) Option {
 	return func(c *configurer) {
-		c.path = path
+		c.paths = nil
+		if path != "" {
+			c.paths = []string{path}
+		}
 	}
 }
 
@@ -118,20 +198,72 @@ func WithFlags(flags []string) Option {
 	}
 }
 
+// WithOptionalFile allows to start without config files, missing files are skipped.
+func WithOptionalFile() Option {
+	return func(c *configurer) {
+		c.optional = true
+	}
+}
+
+// WithDotenv loads the dotenv file into the environment,
+// variables which are already set are not overridden.
+func WithDotenv(path string) Option {
+	return func(c *configurer) {
+		c.dotenvPath = path
+	}
+}
+
 func NewConfigurer(version string, options ...Option) (Configurer, error) {
-	c := &configurer{viper: viper.New(), timeout: DefaultGracefulTimeout, version: version}
+	c, err := newConfigurer(version, options...)
+	if c == nil {
+		return nil, err
+	}
+	return c, err
+}
+
+func newConfigurer(version string, options ...Option) (*configurer, error) {
+	c := &configurer{viper: viper.New(), version: version, options: options, usage: newUsage()}
+	c.timeout.Store(int64(DefaultGracefulTimeout))
 
 	for _, opt := range options {
 		opt(c)
 	}
 
+	if err := c.loadDefaults(); err != nil {
+		return nil, fmt.Errorf("%s %w", OpNew, err)
+	}
+
 	// If user provided []byte data with config, read it and ignore Path and Prefix
 	if c.readInCfg != nil && c.tp != "" {
 		c.viper.SetConfigType(c.tp)
 		err := c.viper.ReadConfig(bytes.NewBuffer(c.readInCfg))
+
+		for _, key := range c.viper.AllKeys() {
+			val := c.viper.Get(key)
+			if c.isDefault(key) {
+				c.setOrigin(key, Origin{Source: SourceDefault, Raw: val, Value: val})
+				continue
+			}
+			c.setOrigin(key, Origin{Source: SourceFile, Raw: val, Value: val})
+		}
+
+		if errP := c.publish(); errP != nil {
+			return nil, fmt.Errorf("%s %w", OpNew, errP)
+		}
+
 		return c, err
 	}
 
+	if c.dotenvPath != "" {
+		if err := c.loadDotenv(); err != nil {
+			return nil, fmt.Errorf("%s %w", OpNew, err)
+		}
+	}
+
+	if c.profile == "" && c.profileEnv != "" {
+		c.profile = os.Getenv(c.profileEnv)
+	}
+
 	// read in environment variables that match
 	c.viper.AutomaticEnv()
 	if c.prefix == "" {
@@ -139,50 +271,50 @@ func NewConfigurer(version string, options ...Option) (Configurer, error) {
 	}
 
 	c.viper.SetEnvPrefix(c.prefix)
-	c.viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	c.viper.SetEnvKeyReplacer(envKeyReplacer)
 
-	if c.path == "" {
+	if len(c.paths) == 0 {
 		ex, err := os.Executable()
 		if err != nil {
 			return nil, fmt.Errorf("%s %w", OpNew, err)
 		}
 		c.viper.AddConfigPath(filepath.Dir(ex))
 		c.viper.AddConfigPath(filepath.Join("/", "etc", filepath.Base(ex)))
-	} else {
-		c.viper.SetConfigFile(c.path)
-	}
 
-	err := c.viper.ReadInConfig()
-	if err != nil {
+		err = c.viper.ReadInConfig()
+		switch {
+		case err == nil:
+			c.loaded = append(c.loaded, c.viper.ConfigFileUsed())
+		case !c.optional || !errors.As(err, &viper.ConfigFileNotFoundError{}):
+			return nil, fmt.Errorf("%s %w", OpNew, err)
+		}
+	} else if err := c.readFiles(); err != nil {
 		return nil, fmt.Errorf("%s %w", OpNew, err)
 	}
 
+	c.loadEnv()
+
 	// automatically inject ENV variables using ${ENV} pattern
 	for _, key := range c.viper.AllKeys() {
 		val := c.viper.Get(key)
-		switch t := val.(type) {
-		case string:
-			// for string just expand it
-			c.viper.Set(key, parseEnvDefault(t))
-		case []interface{}:
-			// for slice -> check if it's slice of strings
-			strArr := make([]string, 0, len(t))
-			for i := 0; i < len(t); i++ {
-				if valStr, ok := t[i].(string); ok {
-					strArr = append(strArr, parseEnvDefault(valStr))
-					continue
-				}
-
-				c.viper.Set(key, val)
-			}
 
-			// we should set the whole array
-			if len(strArr) > 0 {
-				c.viper.Set(key, strArr)
-			}
+		origin, ok := c.envOrigin(key)
+		switch {
+		case ok:
+		case c.isDefault(key):
+			origin = Origin{Source: SourceDefault, Raw: val}
 		default:
-			c.viper.Set(key, val)
+			origin = Origin{Source: SourceFile, Location: c.fileOf(key), Raw: val}
+		}
+
+		res, err := c.expandValue(key, val)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", OpNew, err)
 		}
+		c.viper.Set(key, res)
+
+		origin.Value = c.viper.Get(key)
+		c.setOrigin(key, origin)
 	}
 
 	// override config flags
@@ -191,39 +323,180 @@ func NewConfigurer(version string, options ...Option) (Configurer, error) {
 		if errP != nil {
 			return nil, fmt.Errorf("%s %w", OpNew, errP)
 		}
-		c.viper.Set(key, parseEnvDefault(val))
+		res, err := c.expandValue(key, val)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", OpNew, err)
+		}
+		c.viper.Set(key, res)
+		c.setOrigin(key, Origin{Source: SourceFlag, Raw: val, Value: c.viper.Get(key)})
+	}
+
+	if len(c.missing) > 0 {
+		return nil, fmt.Errorf("%s %w", OpNew, &UndefinedEnvError{Refs: c.missing})
+	}
+
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s %w", OpNew, err)
+	}
+
+	if err := c.publish(); err != nil {
+		return nil, fmt.Errorf("%s %w", OpNew, err)
 	}
 
 	return c, nil
 }
 
-func (cfg *configurer) UnmarshalKey(name string, out interface{}) error {
-	if err := cfg.viper.UnmarshalKey(name, out); err != nil {
-		return fmt.Errorf("%s %w", OpUnmarshalKey, err)
+// publish stores the loaded config as the first snapshot.
+func (cfg *configurer) publish() error {
+	snap, err := newSnapshot(cfg, cfg.viper.AllSettings(), cfg.origins)
+	if err != nil {
+		return err
 	}
+
+	snap.files = cfg.loaded
+
+	cfg.snap.Store(snap)
+
 	return nil
 }
 
+func (cfg *configurer) snapshot() *snapshot {
+	return cfg.snap.Load()
+}
+
+// expandValue walks maps and lists recursively and expands every string leaf,
+// the types of the containers are preserved.
+func (cfg *configurer) expandValue(path string, val interface{}) (interface{}, error) {
+	switch t := val.(type) {
+	case string:
+		res, err := cfg.expand(path, t)
+		if err != nil {
+			return nil, fmt.Errorf("key `%s`: %w", path, err)
+		}
+		return res, nil
+	case []string:
+		out := make([]string, len(t))
+		for i := range t {
+			res, err := cfg.expandValue(joinPath(path, strconv.Itoa(i)), t[i])
+			if err != nil {
+				return nil, err
+			}
+			out[i] = res.(string)
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i := range t {
+			res, err := cfg.expandValue(joinPath(path, strconv.Itoa(i)), t[i])
+			if err != nil {
+				return nil, err
+			}
+			out[i] = res
+		}
+		return out, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			res, err := cfg.expandValue(path+"."+k, v)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = res
+		}
+		return out, nil
+	default:
+		return val, nil
+	}
+}
+
+func (cfg *configurer) UnmarshalKey(name string, out interface{}) error {
+	return cfg.snapshot().UnmarshalKey(name, out)
+}
+
 func (cfg *configurer) Unmarshal(out interface{}) error {
-	if err := cfg.viper.Unmarshal(out); err != nil {
-		return fmt.Errorf("%s %w", OpUnmarshal, err)
+	return cfg.snapshot().Unmarshal(out)
+}
+
+// Overwrite builds a new snapshot with the values, the current one is not modified.
+func (cfg *configurer) Overwrite(values map[string]interface{}) error {
+	prev, next, err := cfg.overwrite(values)
+	if err != nil {
+		return fmt.Errorf("%s %w", OpOverwrite, err)
 	}
+
+	cfg.notify(prev, next)
 	return nil
 }
 
-func (cfg *configurer) Overwrite(values map[string]interface{}) error {
+// overwrite swaps in the snapshot with the values, it returns the previous and the new ones.
+func (cfg *configurer) overwrite(values map[string]interface{}) (*snapshot, *snapshot, error) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	cur := cfg.snapshot()
+
+	origins := make(map[string]Origin, len(cur.origins))
+	for k, o := range cur.origins {
+		origins[k] = o
+	}
+
+	tmp := viper.New()
+	if err := tmp.MergeConfigMap(cur.viper.AllSettings()); err != nil {
+		return nil, nil, err
+	}
+
 	for key, value := range values {
-		cfg.viper.Set(key, value)
+		tmp.Set(key, value)
+		putOrigin(origins, key, Origin{Source: SourceOverwrite, Raw: value, Value: value})
+	}
+
+	// flatten the override layer, so sections are merged for Get
+	next, err := newSnapshot(cfg, tmp.AllSettings(), origins)
+	if err != nil {
+		return nil, nil, err
 	}
+
+	next.files = cur.files
+
+	return cfg.swap(next), next, nil
+}
+
+// Reload reads the config again with the same options and replaces the current snapshot.
+func (cfg *configurer) Reload() error {
+	fresh, err := newConfigurer(cfg.version, cfg.options...)
+	if err != nil {
+		return err
+	}
+
+	next := fresh.snapshot()
+	next.cfg = cfg
+
+	cfg.mu.Lock()
+	prev := cfg.swap(next)
+	cfg.mu.Unlock()
+
+	cfg.notify(prev, next)
 	return nil
 }
 
 func (cfg *configurer) Get(name string) interface{} {
-	return cfg.viper.Get(name)
+	return cfg.snapshot().Get(name)
 }
 
 func (cfg *configurer) Has(name string) bool {
-	return cfg.viper.IsSet(name)
+	return cfg.snapshot().Has(name)
+}
+
+func (cfg *configurer) AllSettings() map[string]interface{} {
+	return cfg.snapshot().AllSettings()
+}
+
+func (cfg *configurer) Snapshot() Configurer {
+	return cfg.snapshot()
+}
+
+func (cfg *configurer) Files() []string {
+	return cfg.snapshot().Files()
 }
 
 func (cfg *configurer) Version() string {
@@ -231,11 +504,11 @@ func (cfg *configurer) Version() string {
 }
 
 func (cfg *configurer) GracefulTimeout() time.Duration {
-	return cfg.timeout
+	return time.Duration(cfg.timeout.Load())
 }
 
 func (cfg *configurer) SetGracefulTimeout(timeout time.Duration) Configurer {
-	cfg.timeout = timeout
+	cfg.timeout.Store(int64(timeout))
 	return cfg
 }
 
@@ -271,8 +544,21 @@ func parseValue(value string) string {
 	return value
 }
 
-func parseEnvDefault(val string) string {
-	// tcp://127.0.0.1:${RPC_PORT:-36643}
-	// for envs like this, part would be tcp://127.0.0.1:
-	return ExpandVal(val, os.Getenv)
+func (cfg *configurer) loadDotenv() error {
+	values, err := godotenv.Read(cfg.dotenvPath)
+	if err != nil {
+		return err
+	}
+
+	cfg.dotenv = make(map[string]string, len(values))
+	for name, value := range values {
+		if _, ok := os.LookupEnv(name); ok {
+			continue
+		}
+		if err = os.Setenv(name, value); err != nil {
+			return err
+		}
+		cfg.dotenv[name] = cfg.dotenvPath
+	}
+	return nil
 }
@@ -0,0 +1,148 @@
+package configwise
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+type sectionDefaults struct {
+	section string
+	value   interface{}
+}
+
+var (
+	defaultsMu sync.RWMutex
+	defaults   []sectionDefaults
+)
+
+// RegisterDefaults registers default values for the config section (empty section is the root),
+// the value is a map or a struct with mapstructure tags, zero fields of the struct are skipped. Defaults are merged under the config
+// files, so they are visible to Get, Has and Unmarshal and are overridden by files, env and flags.
+// It should be called before NewConfigurer, usually from the package init function.
+func RegisterDefaults(section string, value interface{}) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+
+	defaults = append(defaults, sectionDefaults{section: section, value: value})
+}
+
+// WithDefaults registers default values for the config section for the configurer only,
+// they win over the globally registered ones.
+func WithDefaults(section string, value interface{}) Option {
+	return func(c *configurer) {
+		c.defaults = append(c.defaults, sectionDefaults{section: section, value: value})
+	}
+}
+
+func (cfg *configurer) loadDefaults() error {
+	defaultsMu.RLock()
+	all := append(append([]sectionDefaults(nil), defaults...), cfg.defaults...)
+	defaultsMu.RUnlock()
+
+	for _, d := range all {
+		m, err := defaultsMap(d.value)
+		if err != nil {
+			return fmt.Errorf("defaults of `%s`: %w", d.section, err)
+		}
+
+		cfg.setDefaults(strings.ToLower(d.section), m)
+	}
+	return nil
+}
+
+func (cfg *configurer) setDefaults(prefix string, m map[string]interface{}) {
+	for key, val := range m {
+		full := strings.ToLower(key)
+		if prefix != "" {
+			full = prefix + "." + full
+		}
+
+		if sub, ok := val.(map[string]interface{}); ok && len(sub) > 0 {
+			cfg.setDefaults(full, sub)
+			continue
+		}
+
+		if val == nil {
+			continue
+		}
+
+		if cfg.defaultKeys == nil {
+			cfg.defaultKeys = make(map[string]struct{})
+		}
+		cfg.defaultKeys[full] = struct{}{}
+
+		cfg.viper.SetDefault(full, val)
+	}
+}
+
+// isDefault reports whether the value of the key comes from the defaults.
+func (cfg *configurer) isDefault(key string) bool {
+	_, ok := cfg.defaultKeys[key]
+	return ok && !cfg.viper.InConfig(key)
+}
+
+// defaultsMap converts the struct or the map to map, durations are kept as strings.
+func defaultsMap(value interface{}) (map[string]interface{}, error) {
+	var out map[string]interface{}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out = v
+	default:
+		if err := mapstructure.Decode(value, &out); err != nil {
+			return nil, err
+		}
+		out = dropZero(out)
+	}
+
+	return normalizeDefaults(out).(map[string]interface{}), nil
+}
+
+// dropZero removes the zero values decoded from the struct fields, they are not defaults.
+func dropZero(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for key, val := range m {
+		if sub, ok := val.(map[string]interface{}); ok {
+			if sub = dropZero(sub); len(sub) > 0 {
+				out[key] = sub
+			}
+			continue
+		}
+
+		if val == nil || reflect.ValueOf(val).IsZero() {
+			continue
+		}
+		out[key] = val
+	}
+	return out
+}
+
+func normalizeDefaults(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = normalizeDefaults(val)
+		}
+		return out
+	case nil:
+		return nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+
+	return value
+}
@@ -0,0 +1,156 @@
+package configwise
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadEnv materialises the environment variables with the prefix which do not
+// match any key of the config, so they are visible to Has, AllSettings and Unmarshal.
+//
+// The name without the prefix is lower cased, "_" is kept as a part of the key name,
+// so the variables overriding the existing keys are found by the key like viper does.
+// The variable is nested under the deepest known section its name starts with, the
+// sections come from the config files, the defaults and the registered schemas, the
+// first part of the name is the section otherwise. A double "__" nests the key explicitly:
+//
+//	APP_HTTP_READ_TIMEOUT=1s         http.read_timeout: 1s (http is a known section)
+//	APP_CACHE_TTL=5s                 cache.ttl: 5s
+//	APP_CACHE__READ_TIMEOUT=5s       cache.read_timeout: 5s
+//	APP_HTTP__TLS__CERT_FILE=a.pem   http.tls.cert_file: a.pem
+//	APP_HTTP_HOSTS=a,b               http.hosts: "a,b" (unmarshal into []string splits it by ",")
+//	APP_HTTP_HOSTS=["a","b"]         http.hosts: [a, b]
+//	APP_HTTP_HEADERS={"X-Foo":"bar"} http.headers: {x-foo: bar}
+//
+// Variables without a section, like APP_DEBUG, and the ones nested under a value
+// of the config are not materialised, but Get still returns their values.
+// The profile variable is skipped.
+func (cfg *configurer) loadEnv() {
+	if cfg.prefix == "" {
+		return
+	}
+
+	known := make(map[string]struct{})
+	sections := make(map[string]struct{})
+	leaves := make(map[string]struct{})
+	for _, key := range cfg.viper.AllKeys() {
+		leaves[key] = struct{}{}
+
+		parts := strings.Split(key, ".")
+		for i := range parts {
+			known[cfg.envName(strings.Join(parts[:i+1], "."))] = struct{}{}
+			if i < len(parts)-1 {
+				sections[strings.Join(parts[:i+1], ".")] = struct{}{}
+			}
+		}
+	}
+	for _, section := range schemaSections() {
+		sections[section] = struct{}{}
+	}
+
+	prefix := strings.ToUpper(cfg.prefix) + "_"
+
+	var names []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := known[name]; ok || !strings.HasPrefix(name, prefix) || len(name) == len(prefix) || name == cfg.profileEnv {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := envNameToKey(strings.TrimPrefix(name, prefix), sections)
+		if key == "" || underLeaf(key, leaves) {
+			continue
+		}
+
+		if cfg.envKeys == nil {
+			cfg.envKeys = make(map[string]string)
+		}
+		cfg.envKeys[key] = name
+
+		cfg.viper.Set(key, parseEnvValue(os.Getenv(name)))
+	}
+}
+
+// lookupEnv returns the value of the variable overriding the key which is not in the config.
+func (cfg *configurer) lookupEnv(key string) (interface{}, bool) {
+	if cfg.prefix == "" || key == "" {
+		return nil, false
+	}
+
+	val, ok := os.LookupEnv(cfg.envName(key))
+	if !ok {
+		return nil, false
+	}
+	return parseEnvValue(val), true
+}
+
+// envName returns the environment variable name for the key.
+func (cfg *configurer) envName(key string) string {
+	return strings.ToUpper(envKeyReplacer.Replace(cfg.prefix + "_" + key))
+}
+
+// envNameToKey returns the key of the variable name without the prefix,
+// empty if the name has no section.
+func envNameToKey(name string, sections map[string]struct{}) string {
+	name = strings.ToLower(name)
+
+	if strings.Contains(name, "__") {
+		parts := strings.Split(name, "__")
+		for i := range parts {
+			if parts[i] = strings.Trim(parts[i], "_"); parts[i] == "" {
+				return ""
+			}
+		}
+		return strings.Join(parts, ".")
+	}
+
+	var section string
+	for s := range sections {
+		p := envKeyReplacer.Replace(s) + "_"
+		if strings.HasPrefix(name, p) && len(name) > len(p) && len(s) > len(section) {
+			section = s
+		}
+	}
+
+	if section != "" {
+		return section + "." + strings.TrimPrefix(name, envKeyReplacer.Replace(section)+"_")
+	}
+
+	section, key, ok := strings.Cut(name, "_")
+	if !ok || section == "" || strings.Trim(key, "_") != key || key == "" {
+		return ""
+	}
+	return section + "." + key
+}
+
+// underLeaf reports whether the key is nested under a value of the config,
+// setting it would replace the value with a section.
+func underLeaf(key string, leaves map[string]struct{}) bool {
+	parts := strings.Split(key, ".")
+	for i := 1; i < len(parts); i++ {
+		if _, ok := leaves[strings.Join(parts[:i], ".")]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEnvValue decodes JSON lists and maps, other values are kept as is.
+func parseEnvValue(val string) interface{} {
+	trimmed := strings.TrimSpace(val)
+	if trimmed == "" || (trimmed[0] != '[' && trimmed[0] != '{') {
+		return val
+	}
+
+	var out interface{}
+	if err := json.Unmarshal([]byte(trimmed), &out); err != nil {
+		return val
+	}
+	return out
+}
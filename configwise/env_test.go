@@ -0,0 +1,139 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestEnvNameToKey(t *testing.T) {
+	sections := map[string]struct{}{
+		"http":     {},
+		"http.tls": {},
+		"log":      {},
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "HTTP_READ_TIMEOUT", want: "http.read_timeout"},
+		{name: "HTTP_TLS_CERT_FILE", want: "http.tls.cert_file"},
+		{name: "LOG_LEVEL", want: "log.level"},
+		{name: "CACHE__TTL", want: "cache.ttl"},
+		{name: "CACHE__READ_TIMEOUT", want: "cache.read_timeout"},
+		{name: "HTTP__TLS__CERT_FILE", want: "http.tls.cert_file"},
+		{name: "CACHE_TTL", want: "cache.ttl"},
+		{name: "CACHE_READ_TIMEOUT", want: "cache.read_timeout"},
+		{name: "HTTPS_PORT", want: "https.port"},
+		{name: "HTTP", want: ""},
+		{name: "HTTP_", want: ""},
+		{name: "CACHE_", want: ""},
+		{name: "CACHE__TTL_", want: "cache.ttl"},
+		{name: "CACHE____TTL", want: ""},
+		{name: "_CACHE", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := envNameToKey(tt.name, sections); got != tt.want {
+				t.Errorf("envNameToKey(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("debug: true\nhttp:\n  address: x\n  tls:\n    enabled: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("APP_HTTP_ADDRESS", "y")
+	t.Setenv("APP_HTTP_READ_TIMEOUT", "1s")
+	t.Setenv("APP_HTTP_TLS_CERT_FILE", "a.pem")
+	t.Setenv("APP_HTTP_HOSTS", `["a","b"]`)
+	t.Setenv("APP_CACHE__TTL", "5s")
+	t.Setenv("APP_CACHE_SIZE", "10")
+	t.Setenv("APP_METRICS_PORT", "9090")
+	t.Setenv("APP_DEBUG_LEVEL", "1")
+	t.Setenv("APP_VERBOSE", "1")
+	t.Setenv("APP_PROFILE", "dev")
+
+	cfg, err := NewConfigurer("v1",
+		WithPaths(file),
+		WithPrefix("APP"),
+		WithProfileEnv("APP_PROFILE"),
+		WithOptionalFile(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gets := map[string]interface{}{
+		"http.address":       "y",
+		"http.read_timeout":  "1s",
+		"http.tls.cert_file": "a.pem",
+		"http.hosts":         []interface{}{"a", "b"},
+		"cache.ttl":          "5s",
+		"cache.size":         "10",
+		"metrics.port":       "9090",
+		"debug":              true,
+		// not materialised, but found by the key
+		"verbose":     "1",
+		"debug.level": "1",
+	}
+	for key, want := range gets {
+		if got := cfg.Get(key); !reflect.DeepEqual(got, want) {
+			t.Errorf("Get(%q) = %#v, want %#v", key, got, want)
+		}
+		if !cfg.Has(key) {
+			t.Errorf("Has(%q) = false", key)
+		}
+	}
+
+	var http struct {
+		Address     string `mapstructure:"address"`
+		ReadTimeout string `mapstructure:"read_timeout"`
+	}
+	if err = cfg.UnmarshalKey("http", &http); err != nil {
+		t.Fatal(err)
+	}
+	if http.Address != "y" || http.ReadTimeout != "1s" {
+		t.Errorf("UnmarshalKey(http) = %+v", http)
+	}
+
+	var metrics struct {
+		Port int `mapstructure:"port"`
+	}
+	if err = cfg.UnmarshalKey("metrics", &metrics); err != nil {
+		t.Fatal(err)
+	}
+	if metrics.Port != 9090 {
+		t.Errorf("UnmarshalKey(metrics) = %+v", metrics)
+	}
+
+	all := cfg.AllSettings()
+	for _, key := range []string{"cache", "metrics"} {
+		if _, ok := all[key]; !ok || !cfg.Has(key) {
+			t.Errorf("section %q is not materialised", key)
+		}
+	}
+	for _, key := range []string{"profile", "verbose", "metrics_port"} {
+		if _, ok := all[key]; ok {
+			t.Errorf("AllSettings() has unexpected key %q", key)
+		}
+	}
+	if all["debug"] != true {
+		t.Errorf("AllSettings()[debug] = %v, the variable replaced the value", all["debug"])
+	}
+
+	if o, ok := cfg.Origin("http.read_timeout"); !ok || o.Source != SourceEnv || o.Env != "APP_HTTP_READ_TIMEOUT" {
+		t.Errorf("Origin(http.read_timeout) = %+v, %v", o, ok)
+	}
+
+	if cfg.Profile() != "dev" {
+		t.Errorf("Profile() = %q, want dev", cfg.Profile())
+	}
+}
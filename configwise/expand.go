@@ -22,87 +22,256 @@
 
 package configwise
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-const envDefault = ":-"
+var ErrBadSubstitution = errors.New("bad substitution")
+
+// Expander expands shell like parameter references:
+//
+//	$VAR, ${VAR}             value of VAR
+//	${VAR-word}              word if VAR is unset
+//	${VAR:-word}             word if VAR is unset or empty
+//	${VAR=word}              like ${VAR-word}, VAR is assigned the word
+//	${VAR:=word}             like ${VAR:-word}, VAR is assigned the word
+//	${VAR?message}           error with the message if VAR is unset
+//	${VAR:?message}          error with the message if VAR is unset or empty
+//	${VAR+word}              word if VAR is set, empty string otherwise
+//	${VAR:+word}             word if VAR is set and not empty, empty string otherwise
+//	${scheme:ref}            value of ref resolved by the scheme resolver
+//	$$                       literal $
+//
+// The word is expanded as well, so ${A:-${B:-default}} is allowed.
+type Expander struct {
+	// Lookup returns the value of the variable and whether it is set.
+	Lookup func(name string) (string, bool, error)
+	// Assign is called for ${VAR=word} and ${VAR:=word}, nil skips the assignment.
+	Assign func(name, value string) error
+	// Missing is called for $VAR and ${VAR} references to unset variables, might be nil.
+	Missing func(name string)
+	// IsScheme reports whether the name is a resolver scheme, ${scheme:ref} is then
+	// resolved even if ref starts with one of the operators, like ${base64:+Zm9v}.
+	// Might be nil, the operators win in this case.
+	IsScheme func(name string) bool
+	// Lenient drops malformed references and the failing ones instead of returning
+	// an error, the rest of the string is kept, like os.Expand does.
+	Lenient bool
+}
 
 // ExpandVal replaces ${var} or $var in the string based on the mapping function.
 // For example, os.ExpandEnv(s) is equivalent to os.Expand(s, os.Getenv).
+// Empty values are treated as unset, malformed references like an unterminated `${`
+// are dropped and the rest of the string is kept.
 func ExpandVal(s string, mapping func(string) string) string {
-	var buf []byte
-	// ${} is all ASCII, so bytes are fine for this operation.
-	i := 0
-	for j := 0; j < len(s); j++ {
-		if s[j] == '$' && j+1 < len(s) {
-			if buf == nil {
-				buf = make([]byte, 0, 2*len(s))
-			}
-			buf = append(buf, s[i:j]...)
-			name, w := getShellName(s[j+1:])
-			if name == "" && w > 0 { //nolint:revive
-				// Encountered invalid syntax; eat the
-				// characters.
-			} else if name == "" {
-				// Valid syntax, but $ was not followed by a
-				// name. Leave the dollar character untouched.
-				buf = append(buf, s[j])
-				// parse default syntax
-			} else if idx := strings.Index(s, envDefault); idx != -1 {
-				// ${key:-val}
-				substr := strings.Split(name, envDefault)
-				if len(substr) != 2 {
-					return ""
-				}
+	res, _ := Expander{
+		Lookup: func(name string) (string, bool, error) {
+			val := mapping(name)
+			return val, val != "", nil
+		},
+		Lenient: true,
+	}.Expand(s)
+	return res
+}
 
-				key := substr[0]
-				defaultVal := substr[1]
+// Expand is like ExpandVal, but the mapping function might fail,
+// the first error stops the expansion.
+func Expand(s string, mapping func(string) (string, error)) (string, error) {
+	return Expander{Lookup: func(name string) (string, bool, error) {
+		val, err := mapping(name)
+		return val, val != "", err
+	}}.Expand(s)
+}
+
+// Expand replaces the parameter references in the string.
+func (e Expander) Expand(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+
+	var buf strings.Builder
+	buf.Grow(2 * len(s))
 
-				res := mapping(key)
-				if res == "" {
-					res = defaultVal
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 == len(s) {
+			buf.WriteByte(s[i])
+			i++
+			continue
+		}
+
+		switch next := s[i+1]; {
+		case next == '$':
+			buf.WriteByte('$')
+			i += 2
+		case next == '{':
+			end := closingBrace(s, i+2)
+			if end == -1 {
+				if e.Lenient {
+					// eat the `${`
+					i += 2
+					continue
 				}
-				buf = append(buf, res...)
-			} else {
-				buf = append(buf, mapping(name)...)
+				return "", fmt.Errorf("%w: unterminated `${` in `%s`", ErrBadSubstitution, s)
+			}
+
+			val, err := e.param(s[i+2 : end])
+			if err != nil && !e.Lenient {
+				return "", err
 			}
-			j += w
-			i = j + 1
+
+			buf.WriteString(val)
+			i = end + 1
+		case isShellSpecialVar(next):
+			val, err := e.value(s[i+1 : i+2])
+			if err != nil {
+				return "", err
+			}
+
+			buf.WriteString(val)
+			i += 2
+		case isAlphaNum(next):
+			j := i + 1
+			for j < len(s) && isAlphaNum(s[j]) {
+				j++
+			}
+
+			val, err := e.value(s[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+
+			buf.WriteString(val)
+			i = j
+		default:
+			// $ is not followed by a name, leave it untouched
+			buf.WriteByte('$')
+			i++
 		}
 	}
-	if buf == nil {
-		return s
-	}
-	return string(buf) + s[i:]
+
+	return buf.String(), nil
 }
 
-// getShellName returns the name that begins the string and the number of bytes
-// consumed to extract it. If the name is enclosed in {}, it's part of a ${}
-// expansion and two more bytes are needed than the length of the name.
-func getShellName(s string) (string, int) {
-	switch {
-	case s[0] == '{':
-		if len(s) > 2 && isShellSpecialVar(s[1]) && s[2] == '}' {
-			return s[1:2], 3
+// param expands the content of ${...}.
+func (e Expander) param(expr string) (string, error) {
+	name, rest := splitName(expr)
+	if name == "" {
+		return "", fmt.Errorf("%w: `${%s}`", ErrBadSubstitution, expr)
+	}
+
+	if rest == "" {
+		return e.value(name)
+	}
+
+	// ${scheme:ref}
+	if rest[0] == ':' && (len(rest) == 1 || !strings.ContainsRune("-=?+", rune(rest[1])) || e.isScheme(name)) {
+		val, _, err := e.Lookup(expr)
+		return val, err
+	}
+
+	colon := rest[0] == ':'
+	if colon {
+		rest = rest[1:]
+	}
+
+	op, word := rest[0], rest[1:]
+	if !strings.ContainsRune("-=?+", rune(op)) {
+		return "", fmt.Errorf("%w: `${%s}`", ErrBadSubstitution, expr)
+	}
+
+	val, set, err := e.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	unset := !set || (colon && val == "")
+
+	switch op {
+	case '-':
+		if unset {
+			return e.Expand(word)
 		}
-		// Scan to closing brace
-		for i := 1; i < len(s); i++ {
-			if s[i] == '}' {
-				if i == 1 {
-					return "", 2 // Bad syntax; eat "${}"
-				}
-				return s[1:i], i + 1
+	case '=':
+		if unset {
+			if val, err = e.Expand(word); err != nil {
+				return "", err
 			}
+			if e.Assign != nil {
+				err = e.Assign(name, val)
+			}
+			return val, err
+		}
+	case '?':
+		if unset {
+			msg, err := e.Expand(word)
+			if err != nil {
+				return "", err
+			}
+			if msg == "" {
+				msg = "parameter null or not set"
+			}
+			return "", fmt.Errorf("%s: %s", name, msg)
 		}
-		return "", 1 // Bad syntax; eat "${"
-	case isShellSpecialVar(s[0]):
-		return s[0:1], 1
+	case '+':
+		if unset {
+			return "", nil
+		}
+		return e.Expand(word)
 	}
-	// Scan alphanumerics.
-	var i int
-	for i = 0; i < len(s) && isAlphaNum(s[i]); i++ { //nolint:revive
 
+	return val, nil
+}
+
+func (e Expander) isScheme(name string) bool {
+	return e.IsScheme != nil && e.IsScheme(name)
+}
+
+// value returns the value of the plain reference.
+func (e Expander) value(name string) (string, error) {
+	val, set, err := e.Lookup(name)
+	if err == nil && !set && e.Missing != nil {
+		e.Missing(name)
+	}
+	return val, err
+}
+
+// splitName splits the parameter name from the operator part of the expression.
+func splitName(expr string) (string, string) {
+	if expr == "" {
+		return "", ""
+	}
+
+	if isShellSpecialVar(expr[0]) {
+		return expr[:1], expr[1:]
+	}
+
+	i := 0
+	for i < len(expr) && isAlphaNum(expr[i]) {
+		i++
+	}
+	return expr[:i], expr[i:]
+}
+
+// closingBrace returns the index of the brace closing ${ which content starts at the given index.
+func closingBrace(s string, start int) int {
+	depth := 1
+	for j := start; j < len(s); j++ {
+		switch {
+		case s[j] == '$' && j+1 < len(s) && (s[j+1] == '{' || s[j+1] == '$'):
+			if s[j+1] == '{' {
+				depth++
+			}
+			j++
+		case s[j] == '}':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
 	}
-	return s[:i], i
+	return -1
 }
 
 // isShellSpecialVar reports whether the character identifies a special
@@ -0,0 +1,196 @@
+package configwise
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpander(t *testing.T) {
+	env := map[string]string{
+		"SET":   "value",
+		"EMPTY": "",
+		"X":     "x",
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+		// assigned is the variable expected to be assigned
+		assigned string
+		err      error
+		errMsg   string
+	}{
+		{name: "no references", in: "plain text", want: "plain text"},
+		{name: "plain", in: "$SET", want: "value"},
+		{name: "braces", in: "${SET}", want: "value"},
+		{name: "unset", in: "a${UNSET}b", want: "ab"},
+		{name: "dollar escape", in: "cost $$5", want: "cost $5"},
+		{name: "escaped braces", in: "$${SET}", want: "${SET}"},
+		{name: "lone dollar", in: "a $ b", want: "a $ b"},
+		{name: "trailing dollar", in: "a$", want: "a$"},
+		{name: "dollar before non name", in: "a$%b", want: "a$%b"},
+		{name: "text around", in: "http://${SET}:80/$X", want: "http://value:80/x"},
+
+		{name: "dash unset", in: "${UNSET-def}", want: "def"},
+		{name: "dash empty", in: "${EMPTY-def}", want: ""},
+		{name: "dash set", in: "${SET-def}", want: "value"},
+		{name: "colon dash unset", in: "${UNSET:-def}", want: "def"},
+		{name: "colon dash empty", in: "${EMPTY:-def}", want: "def"},
+		{name: "colon dash set", in: "${SET:-def}", want: "value"},
+		{name: "colon dash empty word", in: "${UNSET:-}", want: ""},
+		{name: "colon dash word with colon", in: "${UNSET:-host:80}", want: "host:80"},
+
+		{name: "equals unset", in: "${UNSET=def}", want: "def", assigned: "UNSET"},
+		{name: "equals empty", in: "${EMPTY=def}", want: ""},
+		{name: "equals set", in: "${SET=def}", want: "value"},
+		{name: "colon equals unset", in: "${UNSET:=def}", want: "def", assigned: "UNSET"},
+		{name: "colon equals empty", in: "${EMPTY:=def}", want: "def", assigned: "EMPTY"},
+		{name: "colon equals set", in: "${SET:=def}", want: "value"},
+
+		{name: "question unset", in: "${UNSET?is required}", errMsg: "UNSET: is required"},
+		{name: "question empty", in: "${EMPTY?is required}", want: ""},
+		{name: "question set", in: "${SET?is required}", want: "value"},
+		{name: "colon question unset", in: "${UNSET:?}", errMsg: "UNSET: parameter null or not set"},
+		{name: "colon question empty", in: "${EMPTY:?is required}", errMsg: "EMPTY: is required"},
+		{name: "colon question set", in: "${SET:?is required}", want: "value"},
+
+		{name: "plus unset", in: "${UNSET+alt}", want: ""},
+		{name: "plus empty", in: "${EMPTY+alt}", want: "alt"},
+		{name: "plus set", in: "${SET+alt}", want: "alt"},
+		{name: "colon plus unset", in: "${UNSET:+alt}", want: ""},
+		{name: "colon plus empty", in: "${EMPTY:+alt}", want: ""},
+		{name: "colon plus set", in: "${SET:+alt}", want: "alt"},
+
+		{name: "nested default", in: "${UNSET:-${OTHER:-deep}}", want: "deep"},
+		{name: "nested default set", in: "${UNSET:-${SET:-deep}}", want: "value"},
+		{name: "nested alternative", in: "${SET:+[$X]}", want: "[x]"},
+		{name: "nested three levels", in: "${A:-${B:-${C:-c}}}", want: "c"},
+		{name: "nested question", in: "${UNSET:-${OTHER:?missing}}", errMsg: "OTHER: missing"},
+
+		// the default operator used to be searched in the whole string
+		{name: "default outside reference", in: "a${X}b:-c", want: "axb:-c"},
+		{name: "two references", in: "${UNSET:-a}${X:-b}", want: "ax"},
+		{name: "special var", in: "$1", want: ""},
+
+		{name: "unterminated", in: "cost ${SET", err: ErrBadSubstitution},
+		{name: "unterminated nested", in: "${UNSET:-${SET}", err: ErrBadSubstitution},
+		{name: "empty reference", in: "${}", err: ErrBadSubstitution},
+		{name: "no name", in: "${:-def}", err: ErrBadSubstitution},
+		{name: "unknown operator", in: "${SET%suffix}", err: ErrBadSubstitution},
+		{name: "name ends at operator", in: "${SE-T.x}", want: "T.x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := make(map[string]string, len(env))
+			for k, v := range env {
+				vars[k] = v
+			}
+
+			var assigned string
+			e := Expander{
+				Lookup: func(name string) (string, bool, error) {
+					val, ok := vars[name]
+					return val, ok, nil
+				},
+				Assign: func(name, value string) error {
+					assigned = name
+					vars[name] = value
+					return nil
+				},
+			}
+
+			got, err := e.Expand(tt.in)
+
+			switch {
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Expand(%q) error = %v, want %v", tt.in, err, tt.err)
+				}
+				return
+			case tt.errMsg != "":
+				if err == nil || err.Error() != tt.errMsg {
+					t.Fatalf("Expand(%q) error = %v, want %q", tt.in, err, tt.errMsg)
+				}
+				return
+			case err != nil:
+				t.Fatalf("Expand(%q) unexpected error: %v", tt.in, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if assigned != tt.assigned {
+				t.Errorf("Expand(%q) assigned %q, want %q", tt.in, assigned, tt.assigned)
+			}
+		})
+	}
+}
+
+func TestExpanderScheme(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "scheme", in: "${base64:Zm9v}", want: "resolved(base64:Zm9v)"},
+		{name: "ref starting with plus", in: "${base64:+Zm9v}", want: "resolved(base64:+Zm9v)"},
+		{name: "ref starting with dash", in: "${file:-x}", want: "resolved(file:-x)"},
+		{name: "variable with operator", in: "${VAR:+alt}", want: "alt"},
+		{name: "unknown scheme", in: "${other:ref}", want: "resolved(other:ref)"},
+	}
+
+	e := Expander{
+		Lookup: func(name string) (string, bool, error) {
+			if name == "VAR" {
+				return "var", true, nil
+			}
+			return "resolved(" + name + ")", true, nil
+		},
+		IsScheme: func(name string) bool {
+			return name == "base64" || name == "file"
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := e.Expand(tt.in)
+			if err != nil {
+				t.Fatalf("Expand(%q) unexpected error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandVal(t *testing.T) {
+	mapping := func(name string) string {
+		return map[string]string{"SET": "value", "X": "x"}[name]
+	}
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain", in: "$SET and ${X}", want: "value and x"},
+		{name: "default", in: "${UNSET:-def}", want: "def"},
+		{name: "default outside reference", in: "a${X}b:-c", want: "axb:-c"},
+		{name: "unterminated", in: "cost $$5 ${", want: "cost $5 "},
+		{name: "unterminated reference", in: "a ${SET b", want: "a SET b"},
+		{name: "empty reference", in: "a${}b", want: "ab"},
+		{name: "unknown operator", in: "a${SET%x}b", want: "ab"},
+		{name: "failing question", in: "a${UNSET:?required}b", want: "ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExpandVal(tt.in, mapping); got != tt.want {
+				t.Errorf("ExpandVal(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
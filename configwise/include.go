@@ -0,0 +1,130 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+// IncludeKey is the top level key listing the files to include, the paths are
+// relative to the including file and might be globs or directories. The included
+// files are merged in order before the including file, so it overrides them.
+//
+//	include:
+//	  - http.yaml
+//	  - conf.d/*.yaml
+const IncludeKey = "include"
+
+var ErrIncludeCycle = errors.New("include cycle")
+
+// readFile reads the file with its includes and merges it into the tree,
+// stack holds the files which include the current one.
+func (cfg *configurer) readFile(tree map[string]interface{}, file string, stack []string) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(stack, abs) {
+		return fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(stack, abs), " -> "))
+	}
+
+	v := viper.New()
+	v.SetConfigFile(file)
+	if cfg.tp != "" {
+		v.SetConfigType(cfg.tp)
+	}
+
+	if cfg.template {
+		err = cfg.readTemplate(v, file)
+	} else {
+		err = v.ReadInConfig()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	settings := v.AllSettings()
+
+	warnings, err := Migrate(mapTree(settings))
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+
+	for _, w := range warnings {
+		cfg.warnf("%s: %s, run `config migrate` to upgrade the file", file, w)
+	}
+
+	includes, err := includePaths(file, settings[IncludeKey])
+	if err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	delete(settings, IncludeKey)
+
+	for _, inc := range includes {
+		if err = cfg.readFile(tree, inc, append(stack, abs)); err != nil {
+			if errors.Is(err, ErrIncludeCycle) {
+				return err
+			}
+			return fmt.Errorf("%w (included from %s)", err, file)
+		}
+	}
+
+	cfg.mergeTree(tree, settings, "", file)
+	cfg.loaded = append(cfg.loaded, file)
+
+	return nil
+}
+
+// includePaths returns the files included by the file.
+func includePaths(file string, value interface{}) ([]string, error) {
+	var patterns []string
+
+	switch t := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		patterns = []string{t}
+	case []interface{}:
+		for _, p := range t {
+			s, ok := p.(string)
+			if !ok {
+				return nil, fmt.Errorf("`%s` should be a list of paths, got %v", IncludeKey, p)
+			}
+			patterns = append(patterns, s)
+		}
+	default:
+		return nil, fmt.Errorf("`%s` should be a path or a list of paths, got %v", IncludeKey, value)
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths = append(paths, pattern)
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include `%s`: %w", pattern, err)
+		}
+		sort.Strings(matches)
+
+		paths = append(paths, matches...)
+	}
+
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, fmt.Errorf("include: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,196 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+	"golang.org/x/exp/slices"
+)
+
+// ListMerge defines how lists are merged when several config files are read.
+type ListMerge int
+
+const (
+	// ListReplace replaces the list from the previous files (default).
+	ListReplace ListMerge = iota
+	// ListAppend appends the list items to the list from the previous files.
+	ListAppend
+)
+
+// WithPaths adds config files or directories to read, the files are deep merged
+// in the given order, later files win. Directory files are merged in the lexical order,
+// the profile overlays found there are merged for the active profile only.
+func WithPaths(paths ...string) Option {
+	return func(c *configurer) {
+		c.paths = append(c.paths, paths...)
+	}
+}
+
+func WithListMerge(merge ListMerge) Option {
+	return func(c *configurer) {
+		c.listMerge = merge
+	}
+}
+
+// readFiles reads and merges the config files into the viper instance.
+func (cfg *configurer) readFiles() error {
+	paths := cfg.paths
+	if cfg.optional {
+		paths = existingPaths(paths)
+	}
+
+	files, err := expandPaths(paths)
+	if err != nil {
+		return err
+	}
+
+	files, err = profilePaths(files, cfg.profile)
+	if err != nil && !cfg.optional {
+		return err
+	}
+
+	tree := make(map[string]interface{})
+	for _, file := range files {
+		if err = cfg.readFile(tree, file, nil); err != nil {
+			return err
+		}
+	}
+
+	return cfg.viper.MergeConfigMap(tree)
+}
+
+func (cfg *configurer) mergeTree(dst, src map[string]interface{}, prefix, file string) {
+	for key, val := range src {
+		full := key
+		if prefix != "" {
+			full = prefix + "." + key
+		}
+
+		srcMap, srcIsMap := val.(map[string]interface{})
+		dstMap, dstIsMap := dst[key].(map[string]interface{})
+
+		switch {
+		case srcIsMap && dstIsMap:
+			cfg.mergeTree(dstMap, srcMap, full, file)
+			continue
+		case srcIsMap:
+			dstMap = make(map[string]interface{}, len(srcMap))
+			cfg.setFile(full, "")
+			cfg.mergeTree(dstMap, srcMap, full, file)
+			dst[key] = dstMap
+			continue
+		}
+
+		dstList, dstIsList := dst[key].([]interface{})
+		srcList, srcIsList := val.([]interface{})
+
+		if cfg.listMerge == ListAppend && dstIsList && srcIsList {
+			val = append(slices.Clip(dstList), srcList...)
+		}
+
+		dst[key] = val
+		cfg.setFile(full, file)
+	}
+}
+
+// setFile records the file the key came from, empty file removes the key and its children.
+func (cfg *configurer) setFile(key, file string) {
+	if cfg.files == nil {
+		cfg.files = make(map[string]string)
+	}
+
+	for k := range cfg.files {
+		if k == key || strings.HasPrefix(k, key+".") {
+			delete(cfg.files, k)
+		}
+	}
+
+	if file != "" {
+		cfg.files[key] = file
+	}
+}
+
+// fileOf returns the file the key came from.
+func (cfg *configurer) fileOf(key string) string {
+	if file, ok := cfg.files[key]; ok {
+		return file
+	}
+	return cfg.viper.ConfigFileUsed()
+}
+
+// FindFiles returns the config files of the paths in the merge order, directories are
+// expanded and the overlays of the profile follow their files, nothing is parsed.
+func FindFiles(profile string, paths ...string) ([]string, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+	return profilePaths(files, profile)
+}
+
+func existingPaths(paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			out = append(out, path)
+		}
+	}
+	return out
+}
+
+// expandPaths replaces directories with the config files they contain.
+func expandPaths(paths []string) ([]string, error) {
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var dir []string
+		for _, entry := range entries {
+			ext := strings.TrimPrefix(filepath.Ext(entry.Name()), ".")
+			if entry.IsDir() || !slices.Contains(viper.SupportedExts, ext) {
+				continue
+			}
+			dir = append(dir, filepath.Join(path, entry.Name()))
+		}
+		sort.Strings(dir)
+
+		files = append(files, skipOverlays(dir)...)
+	}
+	return files, nil
+}
+
+// skipOverlays drops the <name>.<profile>.<ext> files of the directory which have
+// the <name>.<ext> file next to them, the overlay of the active profile is added back
+// by profilePaths.
+func skipOverlays(files []string) []string {
+	out := make([]string, 0, len(files))
+	for _, file := range files {
+		ext := filepath.Ext(file)
+		name := strings.TrimSuffix(file, ext)
+
+		if i := strings.LastIndexByte(filepath.Base(name), '.'); i > 0 {
+			base := filepath.Join(filepath.Dir(file), filepath.Base(name)[:i]+ext)
+			if slices.Contains(files, base) {
+				continue
+			}
+		}
+		out = append(out, file)
+	}
+	return out
+}
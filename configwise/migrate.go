@@ -0,0 +1,196 @@
+package configwise
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// VersionKey is the top level key holding the config schema version,
+// files without it are treated as version 1.
+const VersionKey = "config_version"
+
+// ErrTemplateActions is returned by MigrateFile for the template files
+// with the actions outside the quoted values, they must be migrated by hand.
+var ErrTemplateActions = errors.New("template actions outside of the values")
+
+// MigrationFunc rewrites the raw config tree of a file from one version to the next one,
+// it returns the deprecated keys it found.
+//
+//	configwise.RegisterMigration(1, func(tree configwise.Tree) ([]string, error) {
+//		if tree.Move("endure.grace", "endure.grace_period") {
+//			return []string{"endure.grace"}, nil
+//		}
+//		return nil, nil
+//	})
+type MigrationFunc func(tree Tree) (deprecated []string, err error)
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = map[int]MigrationFunc{}
+)
+
+// RegisterMigration registers the migration from the version to version+1.
+func RegisterMigration(from int, fn MigrationFunc) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+
+	migrations[from] = fn
+}
+
+// LatestVersion returns the config version all the registered migrations lead to.
+func LatestVersion() int {
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	latest := 1
+	for from := range migrations {
+		if from+1 > latest {
+			latest = from + 1
+		}
+	}
+	return latest
+}
+
+// Migrate upgrades the raw config tree to the latest version in place, the version key
+// is only set when the tree is upgraded. It returns a warning for every deprecated key.
+func Migrate(tree Tree) ([]string, error) {
+	version, err := treeVersion(tree)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := LatestVersion()
+	if version > latest {
+		return nil, fmt.Errorf("`%s` %d is newer than the supported %d", VersionKey, version, latest)
+	}
+
+	if version == latest {
+		return nil, nil
+	}
+
+	migrationsMu.RLock()
+	defer migrationsMu.RUnlock()
+
+	var warnings []string
+	for ; version < latest; version++ {
+		fn, ok := migrations[version]
+		if !ok {
+			continue
+		}
+
+		deprecated, err := fn(tree)
+		if err != nil {
+			return nil, fmt.Errorf("migrate from version %d: %w", version, err)
+		}
+
+		sort.Strings(deprecated)
+		for _, key := range deprecated {
+			warnings = append(warnings, fmt.Sprintf("key `%s` is deprecated since config version %d", key, version+1))
+		}
+	}
+
+	if err = tree.Set(VersionKey, latest); err != nil {
+		return nil, err
+	}
+
+	return warnings, nil
+}
+
+func treeVersion(tree Tree) (int, error) {
+	v, ok := tree.Get(VersionKey)
+	if !ok {
+		return 1, nil
+	}
+
+	version, err := cast.ToIntE(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid `%s`: %w", VersionKey, err)
+	}
+	return version, nil
+}
+
+// MigrateFile reads the YAML or JSON config file and returns its content upgraded
+// to the latest version, data is nil if the file is already up to date.
+// YAML comments, key order and case are preserved, so are the template actions
+// inside the values. JSON files are rewritten with the sorted keys.
+func MigrateFile(file string) (data []byte, warnings []string, err error) {
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	if ext != "yaml" && ext != "yml" && ext != "json" {
+		return nil, nil, fmt.Errorf("migration of `%s` files is not supported", ext)
+	}
+
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(raw, doc); err != nil {
+		return nil, nil, err
+	}
+
+	// an action outside a string is parsed as a flow mapping and would be lost
+	if countActions(doc) != bytes.Count(raw, []byte("{{")) {
+		return nil, nil, ErrTemplateActions
+	}
+
+	tree, err := newNodeTree(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version, err := treeVersion(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if warnings, err = Migrate(tree); err != nil {
+		return nil, nil, err
+	}
+
+	if version == LatestVersion() {
+		return nil, warnings, nil
+	}
+
+	var buf bytes.Buffer
+	if ext == "json" {
+		var out interface{}
+		if err = doc.Decode(&out); err != nil {
+			return nil, nil, err
+		}
+
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(out)
+	} else {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2) //nolint:gomnd
+		err = enc.Encode(doc)
+	}
+
+	return buf.Bytes(), warnings, err
+}
+
+// WithWarnings sets the handler for the warnings found while loading the config,
+// like deprecated keys.
+func WithWarnings(fn func(msg string)) Option {
+	return func(c *configurer) {
+		c.warn = fn
+	}
+}
+
+func (cfg *configurer) warnf(format string, args ...interface{}) {
+	if cfg.warn != nil {
+		cfg.warn(fmt.Sprintf(format, args...))
+	}
+}
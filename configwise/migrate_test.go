@@ -0,0 +1,101 @@
+package configwise
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateFile(t *testing.T) {
+	RegisterMigration(1, func(tree Tree) ([]string, error) {
+		if tree.Move("endure.grace", "endure.grace_period") {
+			return []string{"endure.grace"}, nil
+		}
+		return nil, nil
+	})
+	t.Cleanup(func() {
+		migrationsMu.Lock()
+		delete(migrations, 1)
+		migrationsMu.Unlock()
+	})
+
+	tests := []struct {
+		name     string
+		file     string
+		in       string
+		want     string
+		warnings int
+		err      error
+	}{
+		{
+			name: "comments and case",
+			file: "config.yaml",
+			in: "# top comment\nendure:\n  # grace comment\n  Grace: 5s # line\n  log_level: info\n" +
+				"HTTP:\n  Address: \"{{ env \\\"X\\\" }}\"\n",
+			want: "# top comment\nconfig_version: 2\nendure:\n  # grace comment\n  grace_period: 5s # line\n  log_level: info\n" +
+				"HTTP:\n  Address: \"{{ env \\\"X\\\" }}\"\n",
+			warnings: 1,
+		},
+		{
+			name:     "flow values",
+			file:     "config.yaml",
+			in:       "endure: {grace: 5s}\n",
+			want:     "config_version: 2\nendure: {grace_period: 5s}\n",
+			warnings: 1,
+		},
+		{
+			name: "no deprecated keys",
+			file: "config.yaml",
+			in:   "http:\n  address: x\n",
+			want: "config_version: 2\nhttp:\n  address: x\n",
+		},
+		{
+			name: "up to date",
+			file: "config.yaml",
+			in:   "config_version: 2\nendure:\n  grace: 5s\n",
+		},
+		{
+			name:     "json",
+			file:     "config.json",
+			in:       `{"endure":{"grace":"1s"},"Keep":{"A":1}}`,
+			want:     "{\n  \"Keep\": {\n    \"A\": 1\n  },\n  \"config_version\": 2,\n  \"endure\": {\n    \"grace_period\": \"1s\"\n  }\n}\n",
+			warnings: 1,
+		},
+		{
+			name: "template action outside a value",
+			file: "config.yaml",
+			in:   "a: {{ .X }}\n",
+			err:  ErrTemplateActions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(file, []byte(tt.in), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			data, warnings, err := MigrateFile(file)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("MigrateFile() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.want == "" && data != nil {
+				t.Errorf("MigrateFile() = %q, want no changes", data)
+			} else if string(data) != tt.want {
+				t.Errorf("MigrateFile() = %q, want %q", data, tt.want)
+			}
+			if len(warnings) != tt.warnings {
+				t.Errorf("MigrateFile() warnings = %q, want %d", warnings, tt.warnings)
+			}
+		})
+	}
+}
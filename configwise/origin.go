@@ -0,0 +1,106 @@
+package configwise
+
+import (
+	"os"
+	"strings"
+)
+
+// Source is the kind of the place a config value came from.
+type Source string
+
+const (
+	SourceDefault   Source = "default"
+	SourceFile      Source = "file"
+	SourceEnv       Source = "env"
+	SourceDotenv    Source = "dotenv"
+	SourceFlag      Source = "flag"
+	SourceOverwrite Source = "overwrite"
+)
+
+// Origin describes where the value of a config key came from.
+type Origin struct {
+	Source Source
+	// Location is the file path for file and dotenv sources.
+	Location string
+	// Env is the variable name for env and dotenv sources.
+	Env string
+	// Raw is the value before ${ENV} expansion.
+	Raw interface{}
+	// Value is the effective value.
+	Value interface{}
+}
+
+func (o Origin) String() string {
+	parts := []string{string(o.Source)}
+	if o.Location != "" {
+		parts = append(parts, o.Location)
+	}
+	if o.Env != "" {
+		parts = append(parts, o.Env)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (cfg *configurer) Origin(name string) (Origin, bool) {
+	return cfg.snapshot().Origin(name)
+}
+
+func (cfg *configurer) setOrigin(key string, o Origin) {
+	if cfg.origins == nil {
+		cfg.origins = make(map[string]Origin)
+	}
+	putOrigin(cfg.origins, key, o)
+}
+
+func putOrigin(origins map[string]Origin, key string, o Origin) {
+	key = normalizeKey(key)
+
+	// the key might replace a whole section
+	for k := range origins {
+		if strings.HasPrefix(k, key+".") {
+			delete(origins, k)
+		}
+	}
+
+	m, ok := o.Value.(map[string]interface{})
+	if !ok {
+		origins[key] = o
+		return
+	}
+
+	for k, v := range m {
+		putOrigin(origins, key+"."+k, Origin{Source: o.Source, Location: o.Location, Raw: v, Value: v})
+	}
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(key)
+}
+
+// envOrigin returns the origin of the environment variable overriding the key.
+func (cfg *configurer) envOrigin(key string) (Origin, bool) {
+	if cfg.prefix == "" {
+		return Origin{}, false
+	}
+
+	// the key might be a part of a JSON map from the environment
+	name, ok := cfg.envKeys[key]
+	for k := key; !ok && strings.Contains(k, "."); {
+		k = k[:strings.LastIndexByte(k, '.')]
+		name, ok = cfg.envKeys[k]
+	}
+
+	if !ok {
+		name = cfg.envName(key)
+	}
+
+	val, ok := os.LookupEnv(name)
+	if !ok {
+		return Origin{}, false
+	}
+
+	if path, ok := cfg.dotenv[name]; ok {
+		return Origin{Source: SourceDotenv, Location: path, Env: name, Raw: val}, true
+	}
+	return Origin{Source: SourceEnv, Env: name, Raw: val}, true
+}
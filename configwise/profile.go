@@ -0,0 +1,57 @@
+package configwise
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithProfile sets the environment profile, for every config file
+// the <name>.<profile>.<ext> file next to it is merged right after it.
+func WithProfile(profile string) Option {
+	return func(c *configurer) {
+		c.profile = profile
+	}
+}
+
+// WithProfileEnv reads the profile from the environment variable when WithProfile
+// does not set one, the variable might come from the dotenv file.
+func WithProfileEnv(name string) Option {
+	return func(c *configurer) {
+		c.profileEnv = name
+	}
+}
+
+func (cfg *configurer) Profile() string {
+	return cfg.profile
+}
+
+// profilePaths inserts the profile overlays after the files they belong to,
+// the directories should be expanded already.
+func profilePaths(paths []string, profile string) ([]string, error) {
+	if profile == "" || len(paths) == 0 {
+		return paths, nil
+	}
+
+	var found bool
+
+	out := make([]string, 0, len(paths)*2)
+	for _, path := range paths {
+		out = append(out, path)
+
+		ext := filepath.Ext(path)
+		overlay := strings.TrimSuffix(path, ext) + "." + profile + ext
+
+		if _, err := os.Stat(overlay); err == nil {
+			out = append(out, overlay)
+			found = true
+		}
+	}
+
+	if !found {
+		return paths, fmt.Errorf("no config file found for profile `%s`", profile)
+	}
+
+	return out, nil
+}
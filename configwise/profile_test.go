@@ -0,0 +1,71 @@
+package configwise
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"config.yaml":         "a: base\nb: base\n",
+		"config.prod.yaml":    "a: prod\n",
+		"config.staging.yaml": "a: staging\nc: staging\n",
+		// no extra.yaml, so it's not an overlay
+		"extra.local.yaml": "d: extra\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		profile string
+		want    map[string]interface{}
+		files   []string
+	}{
+		{
+			name:  "no profile",
+			want:  map[string]interface{}{"a": "base", "b": "base", "d": "extra"},
+			files: []string{"config.yaml", "extra.local.yaml"},
+		},
+		{
+			name:    "prod",
+			profile: "prod",
+			want:    map[string]interface{}{"a": "prod", "b": "base", "d": "extra"},
+			files:   []string{"config.yaml", "config.prod.yaml", "extra.local.yaml"},
+		},
+		{
+			name:    "staging",
+			profile: "staging",
+			want:    map[string]interface{}{"a": "staging", "b": "base", "c": "staging", "d": "extra"},
+			files:   []string{"config.yaml", "config.staging.yaml", "extra.local.yaml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewConfigurer("v1", WithPaths(dir), WithPrefix("APP"), WithProfile(tt.profile))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := cfg.AllSettings(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllSettings() = %v, want %v", got, tt.want)
+			}
+
+			var loaded []string
+			for _, file := range cfg.Files() {
+				loaded = append(loaded, filepath.Base(file))
+			}
+			if !reflect.DeepEqual(loaded, tt.files) {
+				t.Errorf("Files() = %v, want %v", loaded, tt.files)
+			}
+		})
+	}
+}
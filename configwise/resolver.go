@@ -0,0 +1,133 @@
+package configwise
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Resolver resolves scheme prefixed references like ${file:/run/secrets/db_password},
+// ref is the part after the scheme.
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// ResolverFunc is an adapter to use ordinary functions as resolvers.
+type ResolverFunc func(ref string) (string, error)
+
+func (f ResolverFunc) Resolve(ref string) (string, error) {
+	return f(ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		"env":    ResolverFunc(resolveEnv),
+		"file":   ResolverFunc(resolveFile),
+		"base64": ResolverFunc(resolveBase64),
+	}
+)
+
+// RegisterResolver registers the resolver for the scheme globally,
+// it replaces the previously registered one.
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	resolvers[strings.ToLower(scheme)] = r
+}
+
+// WithResolver registers the resolver for the scheme for the configurer only.
+func WithResolver(scheme string, r Resolver) Option {
+	return func(c *configurer) {
+		if c.resolvers == nil {
+			c.resolvers = make(map[string]Resolver)
+		}
+		c.resolvers[strings.ToLower(scheme)] = r
+	}
+}
+
+func (cfg *configurer) resolver(scheme string) (Resolver, bool) {
+	if r, ok := cfg.resolvers[scheme]; ok {
+		return r, true
+	}
+
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	r, ok := resolvers[scheme]
+	return r, ok
+}
+
+func (cfg *configurer) isScheme(name string) bool {
+	_, ok := cfg.resolver(strings.ToLower(name))
+	return ok
+}
+
+// lookup resolves the name of ${name} reference, names with a scheme
+// are resolved by the resolvers, others are environment variables.
+func (cfg *configurer) lookup(name string) (string, bool, error) {
+	if val, ok := cfg.assigned[name]; ok {
+		return val, true, nil
+	}
+
+	if scheme, ref, ok := strings.Cut(name, ":"); ok {
+		r, found := cfg.resolver(strings.ToLower(scheme))
+		if !found {
+			return "", false, fmt.Errorf("unknown resolver scheme `%s`", scheme)
+		}
+
+		val, err := r.Resolve(ref)
+		if err != nil {
+			return "", false, fmt.Errorf("resolve `%s`: %w", name, err)
+		}
+		return val, true, nil
+	}
+
+	val, ok := os.LookupEnv(name)
+	return val, ok, nil
+}
+
+// assign keeps ${VAR:=default} assignments for the following lookups.
+func (cfg *configurer) assign(name, val string) error {
+	if cfg.assigned == nil {
+		cfg.assigned = make(map[string]string)
+	}
+	cfg.assigned[name] = val
+	return nil
+}
+
+// expand expands the value of the key.
+func (cfg *configurer) expand(path, val string) (string, error) {
+	e := Expander{Lookup: cfg.lookup, Assign: cfg.assign, IsScheme: cfg.isScheme}
+
+	if cfg.strictEnv {
+		e.Missing = func(name string) {
+			cfg.missing = append(cfg.missing, EnvRef{Name: name, Path: path})
+		}
+	}
+
+	return e.Expand(val)
+}
+
+func resolveEnv(ref string) (string, error) {
+	return os.Getenv(ref), nil
+}
+
+func resolveFile(ref string) (string, error) {
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveBase64(ref string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(ref)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
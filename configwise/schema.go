@@ -0,0 +1,289 @@
+package configwise
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaDraft is the JSON Schema dialect of the generated schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the values accepted by time.ParseDuration.
+const durationPattern = `^[-+]?(\d+(\.\d*)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+// Schema is a subset of JSON Schema used to describe config files.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+type sectionSchema struct {
+	section string
+	value   interface{}
+}
+
+var (
+	schemasMu sync.RWMutex
+	schemas   []sectionSchema
+)
+
+// RegisterSchema registers the config struct of the section (empty section is the root),
+// it is used to generate the JSON Schema of the config file. Field names come from the
+// mapstructure tags, descriptions from the doc tags and allowed values from the enum tags
+// (comma separated), the defaults are taken from RegisterDefaults.
+//
+//	type Config struct {
+//		Encoding string `mapstructure:"encoding" doc:"log encoding" enum:"json,text,console"`
+//	}
+//
+// It should be called from the package init function.
+func RegisterSchema(section string, value interface{}) {
+	schemasMu.Lock()
+	defer schemasMu.Unlock()
+
+	schemas = append(schemas, sectionSchema{section: section, value: value})
+}
+
+// GenerateSchema returns the JSON Schema of the whole config file built from the registered
+// config structs and defaults. Unknown sections are allowed, they belong to plugins which
+// do not declare their config.
+func GenerateSchema() (*Schema, error) {
+	root := &Schema{
+		Schema: SchemaDraft,
+		Type:   "object",
+		Properties: map[string]*Schema{
+			VersionKey: {
+				Type:        "integer",
+				Description: "config schema version, see `config migrate`",
+				Default:     LatestVersion(),
+			},
+			IncludeKey: {
+				Type:        "array",
+				Description: "config files merged under this file, relative to it",
+				Items:       &Schema{Type: "string"},
+			},
+		},
+	}
+
+	schemasMu.RLock()
+	registered := append([]sectionSchema(nil), schemas...)
+	schemasMu.RUnlock()
+
+	for _, s := range registered {
+		t := reflect.TypeOf(s.value)
+		section := strings.ToLower(s.section)
+
+		dst := root
+		if section != "" {
+			dst = root.section(section)
+		}
+		dst.merge(schemaOf(t))
+	}
+
+	defaultsMu.RLock()
+	all := append([]sectionDefaults(nil), defaults...)
+	defaultsMu.RUnlock()
+
+	for _, d := range all {
+		m, err := defaultsMap(d.value)
+		if err != nil {
+			return nil, err
+		}
+		root.setDefaults(strings.ToLower(d.section), m)
+	}
+
+	return root, nil
+}
+
+// schemaSections returns the dotted paths of the sections declared by the registered config structs.
+func schemaSections() []string {
+	schemasMu.RLock()
+	registered := append([]sectionSchema(nil), schemas...)
+	schemasMu.RUnlock()
+
+	var out []string
+	var walk func(path string, s *Schema)
+	walk = func(path string, s *Schema) {
+		if path != "" {
+			out = append(out, path)
+		}
+		for key, prop := range s.Properties {
+			if prop.Type == "object" {
+				walk(joinPath(path, key), prop)
+			}
+		}
+	}
+
+	for _, s := range registered {
+		walk(strings.ToLower(s.section), schemaOf(reflect.TypeOf(s.value)))
+	}
+	return out
+}
+
+// section returns the schema of the dotted path, the missing objects are created.
+func (s *Schema) section(path string) *Schema {
+	cur := s
+	for _, key := range strings.Split(path, ".") {
+		if cur.Properties == nil {
+			cur.Properties = make(map[string]*Schema)
+		}
+		next, ok := cur.Properties[key]
+		if !ok {
+			next = &Schema{Type: "object"}
+			cur.Properties[key] = next
+		}
+		cur = next
+	}
+	return cur
+}
+
+// lookup returns the schema of the dotted path or nil.
+func (s *Schema) lookup(path string) *Schema {
+	cur := s
+	for _, key := range strings.Split(path, ".") {
+		switch {
+		case cur.Properties[key] != nil:
+			cur = cur.Properties[key]
+		case cur.AdditionalProperties != nil:
+			cur = cur.AdditionalProperties
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+func (s *Schema) merge(src *Schema) {
+	if src.Description != "" {
+		s.Description = src.Description
+	}
+	if src.Type != "" {
+		s.Type = src.Type
+	}
+	if src.AdditionalProperties != nil {
+		s.AdditionalProperties = src.AdditionalProperties
+	}
+	for key, prop := range src.Properties {
+		if s.Properties == nil {
+			s.Properties = make(map[string]*Schema)
+		}
+		if dst, ok := s.Properties[key]; ok {
+			dst.merge(prop)
+			continue
+		}
+		s.Properties[key] = prop
+	}
+}
+
+func (s *Schema) setDefaults(prefix string, m map[string]interface{}) {
+	for key, val := range m {
+		full := strings.ToLower(key)
+		if prefix != "" {
+			full = prefix + "." + full
+		}
+
+		if sub, ok := val.(map[string]interface{}); ok && len(sub) > 0 {
+			s.setDefaults(full, sub)
+			continue
+		}
+
+		if prop := s.lookup(full); prop != nil && val != nil {
+			prop.Default = val
+		}
+	}
+}
+
+// schemaOf describes the type the way mapstructure decodes it with the configurer hooks.
+func schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// durations are decoded from strings and from integer nanoseconds,
+	// pattern applies to strings only
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return &Schema{Pattern: durationPattern}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(t.Elem())}
+	case reflect.Map:
+		s := &Schema{Type: "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s.AdditionalProperties = schemaOf(t.Elem())
+		}
+		return s
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		structFields(t, s)
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+func structFields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if opts == "squash" || (field.Anonymous && name == "") {
+			ft := field.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				structFields(ft, s)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := schemaOf(field.Type)
+		prop.Description = field.Tag.Get("doc")
+
+		if enum := field.Tag.Get("enum"); enum != "" {
+			for _, v := range strings.Split(enum, ",") {
+				prop.Enum = append(prop.Enum, strings.TrimSpace(v))
+			}
+		}
+
+		s.Properties[strings.ToLower(name)] = prop
+	}
+}
@@ -0,0 +1,116 @@
+package configwise
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var _ Configurer = (*snapshot)(nil)
+
+var ErrReadOnly = errors.New("config snapshot is read-only")
+
+// snapshot is an immutable state of the config, the configurer replaces
+// the whole snapshot on every write, so readers never see partial updates.
+type snapshot struct {
+	cfg     *configurer
+	viper   *viper.Viper
+	origins map[string]Origin
+	files   []string
+}
+
+func newSnapshot(cfg *configurer, settings map[string]interface{}, origins map[string]Origin) (*snapshot, error) {
+	v := viper.New()
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, err
+	}
+	return &snapshot{cfg: cfg, viper: v, origins: origins}, nil
+}
+
+func (s *snapshot) UnmarshalKey(name string, out interface{}) error {
+	if err := s.cfg.usage.unmarshal(s.viper, name, out); err != nil {
+		return fmt.Errorf("%s %w", OpUnmarshalKey, err)
+	}
+	return nil
+}
+
+func (s *snapshot) Unmarshal(out interface{}) error {
+	if err := s.cfg.usage.unmarshal(s.viper, "", out); err != nil {
+		return fmt.Errorf("%s %w", OpUnmarshal, err)
+	}
+	return nil
+}
+
+func (s *snapshot) Overwrite(map[string]interface{}) error {
+	return fmt.Errorf("%s %w", OpOverwrite, ErrReadOnly)
+}
+
+func (s *snapshot) Get(name string) interface{} {
+	s.cfg.usage.get(name)
+	if !s.viper.IsSet(name) {
+		if val, ok := s.cfg.lookupEnv(normalizeKey(name)); ok {
+			return val
+		}
+	}
+	return s.viper.Get(name)
+}
+
+func (s *snapshot) Has(name string) bool {
+	if s.viper.IsSet(name) {
+		return true
+	}
+	_, ok := s.cfg.lookupEnv(normalizeKey(name))
+	return ok
+}
+
+func (s *snapshot) AllSettings() map[string]interface{} {
+	return s.viper.AllSettings()
+}
+
+func (s *snapshot) Origin(name string) (Origin, bool) {
+	o, ok := s.origins[normalizeKey(name)]
+	return o, ok
+}
+
+func (s *snapshot) Sub(key string) Configurer {
+	return newSub(s, key)
+}
+
+func (s *snapshot) Snapshot() Configurer {
+	return s
+}
+
+func (s *snapshot) Reload() error {
+	return ErrReadOnly
+}
+
+func (s *snapshot) Watch(prefix string, fn func(old, new interface{})) func() {
+	return s.cfg.Watch(prefix, fn)
+}
+
+func (s *snapshot) Files() []string {
+	return s.files
+}
+
+func (s *snapshot) Profile() string {
+	return s.cfg.Profile()
+}
+
+func (s *snapshot) GracefulTimeout() time.Duration {
+	return s.cfg.GracefulTimeout()
+}
+
+func (s *snapshot) SetGracefulTimeout(timeout time.Duration) Configurer {
+	s.cfg.SetGracefulTimeout(timeout)
+	return s
+}
+
+func (s *snapshot) UnusedKeys() []UnusedKey {
+	return s.cfg.UnusedKeys()
+}
+
+func (s *snapshot) Version() string {
+	return s.cfg.Version()
+}
@@ -0,0 +1,90 @@
+package configwise
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentAccess should be run with -race.
+func TestConcurrentAccess(t *testing.T) {
+	cfg, err := NewConfigurer("v1",
+		WithConfigType("yaml"),
+		WithReadInCfg([]byte("http:\n  address: localhost\n  port: 80\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		workers    = 4
+		iterations = 200
+	)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(4)
+
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				err := cfg.Overwrite(map[string]interface{}{
+					"http.port":             8000 + i,
+					fmt.Sprintf("w%d.i", w): i,
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				if cfg.Get("http.address") != "localhost" {
+					t.Error("Get(http.address) lost the value")
+					return
+				}
+				_ = cfg.Get("http")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				var http struct {
+					Address string `mapstructure:"address"`
+					Port    int    `mapstructure:"port"`
+				}
+				if err := cfg.UnmarshalKey("http", &http); err != nil {
+					t.Error(err)
+					return
+				}
+				if http.Address != "localhost" || http.Port == 0 {
+					t.Errorf("UnmarshalKey(http) = %+v", http)
+					return
+				}
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			sub := cfg.Sub("http")
+			for i := 0; i < iterations; i++ {
+				if sub.Get("address") != "localhost" {
+					t.Error("Sub(http).Get(address) lost the value")
+					return
+				}
+				_ = sub.AllSettings()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if got := cfg.Get("http.port"); got != 8000+iterations-1 {
+		t.Errorf("Get(http.port) = %v, want %d", got, 8000+iterations-1)
+	}
+}
@@ -0,0 +1,38 @@
+package configwise
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// EnvRef is a reference to an environment variable in the config.
+type EnvRef struct {
+	Name string
+	// Path is the dotted config key of the value containing the reference,
+	// list items are addressed by index, like `http.hosts.0`.
+	Path string
+}
+
+// UndefinedEnvError lists the references to unset variables without a default.
+type UndefinedEnvError struct {
+	Refs []EnvRef
+}
+
+func (e *UndefinedEnvError) Error() string {
+	refs := make([]string, 0, len(e.Refs))
+	for _, ref := range e.Refs {
+		refs = append(refs, fmt.Sprintf("%s (%s)", ref.Name, ref.Path))
+	}
+	sort.Strings(refs)
+
+	return "undefined environment variables: " + strings.Join(refs, ", ")
+}
+
+// WithStrictEnv makes NewConfigurer fail when the config references unset
+// environment variables without a default, like ${DB_PASSWORD}.
+func WithStrictEnv() Option {
+	return func(c *configurer) {
+		c.strictEnv = true
+	}
+}
@@ -0,0 +1,121 @@
+package configwise
+
+import (
+	"strings"
+	"time"
+)
+
+var _ Configurer = (*subConfigurer)(nil)
+
+// subConfigurer is a view of the parent configurer rooted at the key.
+type subConfigurer struct {
+	parent Configurer
+	root   string
+}
+
+func (cfg *configurer) Sub(key string) Configurer {
+	return newSub(cfg, key)
+}
+
+func newSub(parent Configurer, root string) Configurer {
+	return &subConfigurer{parent: parent, root: strings.ToLower(root)}
+}
+
+func (s *subConfigurer) key(name string) string {
+	switch {
+	case name == "":
+		return s.root
+	case s.root == "":
+		return name
+	default:
+		return s.root + "." + name
+	}
+}
+
+func (s *subConfigurer) UnmarshalKey(name string, out interface{}) error {
+	return s.parent.UnmarshalKey(s.key(name), out)
+}
+
+func (s *subConfigurer) Unmarshal(out interface{}) error {
+	if s.root == "" {
+		return s.parent.Unmarshal(out)
+	}
+	return s.parent.UnmarshalKey(s.root, out)
+}
+
+func (s *subConfigurer) Overwrite(values map[string]interface{}) error {
+	prefixed := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		prefixed[s.key(key)] = value
+	}
+	return s.parent.Overwrite(prefixed)
+}
+
+func (s *subConfigurer) Get(name string) interface{} {
+	return s.parent.Get(s.key(name))
+}
+
+func (s *subConfigurer) Has(name string) bool {
+	return s.parent.Has(s.key(name))
+}
+
+func (s *subConfigurer) AllSettings() map[string]interface{} {
+	m := s.parent.AllSettings()
+	if s.root == "" {
+		return m
+	}
+
+	for _, part := range strings.Split(s.root, ".") {
+		sub, ok := m[part].(map[string]interface{})
+		if !ok {
+			return map[string]interface{}{}
+		}
+		m = sub
+	}
+	return m
+}
+
+func (s *subConfigurer) Origin(name string) (Origin, bool) {
+	return s.parent.Origin(s.key(name))
+}
+
+func (s *subConfigurer) Sub(key string) Configurer {
+	return newSub(s.parent, s.key(key))
+}
+
+func (s *subConfigurer) Snapshot() Configurer {
+	return newSub(s.parent.Snapshot(), s.root)
+}
+
+func (s *subConfigurer) Reload() error {
+	return s.parent.Reload()
+}
+
+func (s *subConfigurer) Watch(prefix string, fn func(old, new interface{})) func() {
+	return s.parent.Watch(s.key(prefix), fn)
+}
+
+func (s *subConfigurer) Files() []string {
+	return s.parent.Files()
+}
+
+func (s *subConfigurer) Profile() string {
+	return s.parent.Profile()
+}
+
+func (s *subConfigurer) GracefulTimeout() time.Duration {
+	return s.parent.GracefulTimeout()
+}
+
+func (s *subConfigurer) SetGracefulTimeout(timeout time.Duration) Configurer {
+	s.parent.SetGracefulTimeout(timeout)
+	return s
+}
+
+func (s *subConfigurer) UnusedKeys() []UnusedKey {
+	return s.parent.UnusedKeys()
+}
+
+func (s *subConfigurer) Version() string {
+	return s.parent.Version()
+}
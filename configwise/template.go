@@ -0,0 +1,112 @@
+package configwise
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/viper"
+)
+
+// WithTemplate renders the config files with text/template before parsing,
+// see TemplateFuncs for the available functions:
+//
+//	http:
+//	  address: {{ env "HOST" | default "0.0.0.0" }}:{{ add 8000 (atoi (env "SHARD")) }}
+//	regions:
+//	{{- range split "eu,us" "," }}
+//	  - {{ . }}
+//	{{- end }}
+func WithTemplate() Option {
+	return func(c *configurer) {
+		c.template = true
+	}
+}
+
+// TemplateFuncs returns the functions available in the config templates.
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"env": os.Getenv,
+		"default": func(def, val interface{}) interface{} {
+			if val == nil || fmt.Sprint(val) == "" {
+				return def
+			}
+			return val
+		},
+		"hostname": os.Hostname,
+		"atoi":     strconv.Atoi,
+		"add":      func(a, b int) int { return a + b },
+		"sub":      func(a, b int) int { return a - b },
+		"mul":      func(a, b int) int { return a * b },
+		"div": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return a / b, nil
+		},
+		"mod": func(a, b int) (int, error) {
+			if b == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return a % b, nil
+		},
+		"seq": func(n int) []int {
+			out := make([]int, n)
+			for i := range out {
+				out[i] = i
+			}
+			return out
+		},
+		"split": strings.Split,
+		"join":  func(sep string, items []string) string { return strings.Join(items, sep) },
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"trim":  strings.TrimSpace,
+		"quote": strconv.Quote,
+	}
+}
+
+// readTemplate renders the file and reads the result into viper.
+func (cfg *configurer) readTemplate(v *viper.Viper, file string) error {
+	data, err := renderFile(file)
+	if err != nil {
+		return err
+	}
+
+	if cfg.rendered == nil {
+		cfg.rendered = make(map[string][]byte)
+	}
+	cfg.rendered[file] = data
+
+	if cfg.tp == "" {
+		v.SetConfigType(strings.TrimPrefix(filepath.Ext(file), "."))
+	}
+
+	return v.ReadConfig(bytes.NewReader(data))
+}
+
+// renderFile renders the config file template.
+func renderFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(file)).
+		Funcs(TemplateFuncs()).
+		Option("missingkey=error").
+		Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
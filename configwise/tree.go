@@ -0,0 +1,278 @@
+package configwise
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tree is the raw config tree of a file passed to the migrations,
+// the keys are dotted and case-insensitive.
+type Tree interface {
+	// Get returns the value of the key.
+	Get(key string) (interface{}, bool)
+	// Set sets the value of the key, creating the missing sections.
+	Set(key string, value interface{}) error
+	// Delete removes the key and the sections left empty, it reports whether the key existed.
+	Delete(key string) bool
+	// Move moves the value of the key to another one, it reports whether the key existed.
+	Move(from, to string) bool
+}
+
+// mapTree is the tree of the viper settings read from a file.
+type mapTree map[string]interface{}
+
+func (t mapTree) Get(key string) (interface{}, bool) {
+	parts := strings.Split(normalizeKey(key), ".")
+
+	m := map[string]interface{}(t)
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := m[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		m = sub
+	}
+
+	val, ok := m[parts[len(parts)-1]]
+	return val, ok
+}
+
+func (t mapTree) Set(key string, value interface{}) error {
+	parts := strings.Split(normalizeKey(key), ".")
+
+	m := map[string]interface{}(t)
+	for i, part := range parts[:len(parts)-1] {
+		switch sub := m[part].(type) {
+		case map[string]interface{}:
+			m = sub
+		case nil:
+			next := make(map[string]interface{})
+			m[part] = next
+			m = next
+		default:
+			return fmt.Errorf("`%s` is not a section", strings.Join(parts[:i+1], "."))
+		}
+	}
+	m[parts[len(parts)-1]] = value
+
+	return nil
+}
+
+func (t mapTree) Delete(key string) bool {
+	_, ok := t.remove(key)
+	return ok
+}
+
+func (t mapTree) Move(from, to string) bool {
+	val, ok := t.remove(from)
+	if !ok {
+		return false
+	}
+	return t.Set(to, val) == nil
+}
+
+func (t mapTree) remove(key string) (interface{}, bool) {
+	return removeMapKey(t, strings.Split(normalizeKey(key), "."))
+}
+
+func removeMapKey(m map[string]interface{}, parts []string) (interface{}, bool) {
+	if len(parts) == 1 {
+		val, ok := m[parts[0]]
+		delete(m, parts[0])
+		return val, ok
+	}
+
+	sub, ok := m[parts[0]].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	val, ok := removeMapKey(sub, parts[1:])
+	if ok && len(sub) == 0 {
+		delete(m, parts[0])
+	}
+	return val, ok
+}
+
+// nodeTree is the tree of a YAML document, the comments and the case of the keys
+// are kept, the key node keeps its comments when the key is moved.
+type nodeTree struct {
+	root *yaml.Node
+}
+
+func newNodeTree(doc *yaml.Node) (*nodeTree, error) {
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+	}
+
+	if doc.Kind != yaml.DocumentNode {
+		return nil, fmt.Errorf("unexpected yaml node kind %d", doc.Kind)
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"})
+	}
+
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the config root is not a mapping")
+	}
+
+	return &nodeTree{root: doc.Content[0]}, nil
+}
+
+func (t *nodeTree) Get(key string) (interface{}, bool) {
+	_, val := t.lookup(strings.Split(key, "."))
+	if val == nil {
+		return nil, false
+	}
+
+	var out interface{}
+	if err := val.Decode(&out); err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+func (t *nodeTree) Set(key string, value interface{}) error {
+	val := &yaml.Node{}
+	if err := val.Encode(value); err != nil {
+		return err
+	}
+	return t.set(strings.Split(key, "."), nil, val)
+}
+
+func (t *nodeTree) Delete(key string) bool {
+	k, _ := t.remove(t.root, strings.Split(key, "."))
+	return k != nil
+}
+
+func (t *nodeTree) Move(from, to string) bool {
+	src, dst := strings.Split(from, "."), strings.Split(to, ".")
+
+	// a key renamed within its section keeps its place
+	section := strings.Join(src[:len(src)-1], ".")
+	if strings.EqualFold(section, strings.Join(dst[:len(dst)-1], ".")) {
+		k, _ := t.lookup(src)
+		if existing, _ := t.lookup(dst); k != nil && existing == nil {
+			k.Value = dst[len(dst)-1]
+			return true
+		}
+	}
+
+	k, v := t.remove(t.root, src)
+	if k == nil {
+		return false
+	}
+	k.Value = dst[len(dst)-1]
+
+	return t.set(dst, k, v) == nil
+}
+
+// lookup returns the key and value nodes of the dotted key.
+func (t *nodeTree) lookup(parts []string) (*yaml.Node, *yaml.Node) {
+	m := t.root
+	for i, part := range parts {
+		k, v := mappingPair(m, part)
+		if k == nil || i == len(parts)-1 {
+			return k, v
+		}
+		if v.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		m = v
+	}
+	return nil, nil
+}
+
+// set sets the value node of the key, the key node is only used for a new key.
+func (t *nodeTree) set(parts []string, key, val *yaml.Node) error {
+	m := t.root
+	for i, part := range parts[:len(parts)-1] {
+		_, v := mappingPair(m, part)
+		if v == nil {
+			v = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, v)
+		} else if v.Kind != yaml.MappingNode {
+			return fmt.Errorf("`%s` is not a section", strings.Join(parts[:i+1], "."))
+		}
+		m = v
+	}
+
+	name := parts[len(parts)-1]
+	if _, old := mappingPair(m, name); old != nil {
+		val.HeadComment, val.LineComment, val.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		*old = *val
+		return nil
+	}
+
+	if key == nil {
+		key = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name}
+	}
+
+	// a new version key goes first and takes the comment of the file
+	if m == t.root && strings.EqualFold(name, VersionKey) {
+		if len(m.Content) > 0 {
+			key.HeadComment, m.Content[0].HeadComment = m.Content[0].HeadComment, ""
+		}
+		m.Content = append([]*yaml.Node{key, val}, m.Content...)
+		return nil
+	}
+
+	m.Content = append(m.Content, key, val)
+	return nil
+}
+
+// remove removes the key from the mapping and the sections left empty,
+// it returns the removed key and value nodes.
+func (t *nodeTree) remove(m *yaml.Node, parts []string) (*yaml.Node, *yaml.Node) {
+	i := mappingIndex(m, parts[0])
+	if i < 0 {
+		return nil, nil
+	}
+
+	k, v := m.Content[i], m.Content[i+1]
+	if len(parts) > 1 {
+		if v.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		k, v = t.remove(v, parts[1:])
+		if k == nil || len(m.Content[i+1].Content) > 0 {
+			return k, v
+		}
+	}
+
+	m.Content = append(m.Content[:i], m.Content[i+2:]...)
+	return k, v
+}
+
+func mappingPair(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	i := mappingIndex(m, key)
+	if i < 0 {
+		return nil, nil
+	}
+	return m.Content[i], m.Content[i+1]
+}
+
+func mappingIndex(m *yaml.Node, key string) int {
+	if m.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if strings.EqualFold(m.Content[i].Value, key) {
+			return i
+		}
+	}
+	return -1
+}
+
+// countActions counts the template actions inside the values and comments of the node.
+func countActions(n *yaml.Node) int {
+	count := strings.Count(n.Value, "{{") + strings.Count(n.HeadComment, "{{") +
+		strings.Count(n.LineComment, "{{") + strings.Count(n.FootComment, "{{")
+	for _, c := range n.Content {
+		count += countActions(c)
+	}
+	return count
+}
@@ -0,0 +1,245 @@
+package configwise
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// UnusedKey is a config key which was not read by any plugin.
+type UnusedKey struct {
+	Key    string
+	Origin Origin
+	// Suggestion is the known key with the closest name, empty if none is close enough.
+	Suggestion string
+}
+
+func (k UnusedKey) String() string {
+	s := fmt.Sprintf("`%s` (%s)", k.Key, k.Origin)
+	if k.Suggestion != "" {
+		s += fmt.Sprintf(", did you mean `%s`?", k.Suggestion)
+	}
+	return s
+}
+
+// UnusedKeysError lists the config keys nobody read.
+type UnusedKeysError struct {
+	Keys []UnusedKey
+}
+
+func (e *UnusedKeysError) Error() string {
+	keys := make([]string, 0, len(e.Keys))
+	for _, k := range e.Keys {
+		keys = append(keys, "\n  "+k.String())
+	}
+	return "unknown config keys:" + strings.Join(keys, "")
+}
+
+// usage records the keys read from the config.
+type usage struct {
+	mu sync.Mutex
+	// stopped is set by UnusedKeys, the reads are not recorded anymore
+	stopped bool
+	// read holds the keys read by Get, the whole subtree counts as read
+	read map[string]struct{}
+	// decoded holds the prefixes of UnmarshalKey and Unmarshal,
+	// the subtree counts as read except the unused keys
+	decoded map[string]struct{}
+	// unused holds the keys the target structs have no field for
+	unused map[string]struct{}
+	// known holds the keys the target structs have a field for
+	known map[string]struct{}
+}
+
+func newUsage() *usage {
+	return &usage{
+		read:    make(map[string]struct{}),
+		decoded: make(map[string]struct{}),
+		unused:  make(map[string]struct{}),
+		known:   make(map[string]struct{}),
+	}
+}
+
+func (u *usage) get(name string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.stopped {
+		u.read[normalizeKey(name)] = struct{}{}
+	}
+}
+
+// unmarshal decodes the key with the metadata, the key is recorded with the fields of the target.
+func (u *usage) unmarshal(v *viper.Viper, name string, out interface{}) error {
+	u.mu.Lock()
+	stopped := u.stopped
+	u.mu.Unlock()
+
+	if stopped {
+		if name == "" {
+			return v.Unmarshal(out)
+		}
+		return v.UnmarshalKey(name, out)
+	}
+
+	md := &mapstructure.Metadata{}
+	withMetadata := func(dc *mapstructure.DecoderConfig) {
+		dc.Metadata = md
+	}
+
+	var err error
+	if name == "" {
+		err = v.Unmarshal(out, withMetadata)
+	} else {
+		err = v.UnmarshalKey(name, out, withMetadata)
+	}
+	if err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	prefix := normalizeKey(name)
+	u.decoded[prefix] = struct{}{}
+
+	for _, key := range md.Unused {
+		u.unused[metadataKey(prefix, key)] = struct{}{}
+	}
+	for _, keys := range [][]string{md.Keys, md.Unset} {
+		for _, key := range keys {
+			u.known[metadataKey(prefix, key)] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// isRead reports whether the key was read.
+func (u *usage) isRead(key string) bool {
+	if _, ok := u.known[key]; ok {
+		return true
+	}
+
+	parents := []string{key}
+	for k := key; strings.Contains(k, "."); {
+		k = k[:strings.LastIndexByte(k, '.')]
+		parents = append(parents, k)
+	}
+
+	for _, k := range parents {
+		if _, ok := u.read[k]; ok {
+			return true
+		}
+	}
+
+	for _, k := range append(parents, "") {
+		if _, ok := u.decoded[k]; ok {
+			return true
+		}
+		if _, ok := u.unused[k]; ok {
+			return false
+		}
+	}
+	return false
+}
+
+// suggest returns the known key with the same parent and the closest name.
+func (u *usage) suggest(key string) string {
+	parent, name := "", key
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		parent, name = key[:i], key[i+1:]
+	}
+
+	best, bestDist := "", len(name)/3+1 //nolint:gomnd
+	for known := range u.known {
+		kp, kn := "", known
+		if i := strings.LastIndexByte(known, '.'); i >= 0 {
+			kp, kn = known[:i], known[i+1:]
+		}
+		if kp != parent {
+			continue
+		}
+
+		if d := editDistance(name, kn); d < bestDist || (d == bestDist && known < best) {
+			best, bestDist = known, d
+		}
+	}
+	return best
+}
+
+// UnusedKeys stops recording the reads and returns the keys from the config files and
+// the flags which were not read by UnmarshalKey, Unmarshal or Get since the configurer
+// was created. Keys from the environment are not reported, the variables with the prefix
+// might hold settings which are not a part of the config. Call it after the plugins
+// read their config, like after the container Init.
+func (cfg *configurer) UnusedKeys() []UnusedKey {
+	u := cfg.usage
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.stopped = true
+
+	snap := cfg.snapshot()
+
+	var out []UnusedKey
+	for _, key := range snap.viper.AllKeys() {
+		o, ok := snap.origins[key]
+		if !ok || (o.Source != SourceFile && o.Source != SourceFlag) {
+			continue
+		}
+
+		if key == VersionKey || u.isRead(key) {
+			continue
+		}
+
+		out = append(out, UnusedKey{Key: key, Origin: o, Suggestion: u.suggest(key)})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+
+	return out
+}
+
+// metadataKey converts the mapstructure field name like `plugins[http].startup_timeout` to the dotted key.
+func metadataKey(prefix, name string) string {
+	name = strings.NewReplacer("[", ".", "]", "").Replace(name)
+	return normalizeKey(joinPath(prefix, name))
+}
+
+// editDistance is the optimal string alignment distance, a swap of the adjacent
+// characters is a single edit, it's the most common typo.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// rows i-2, i-1 and i of the distance matrix
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
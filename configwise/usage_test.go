@@ -0,0 +1,146 @@
+package configwise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnusedKeys(t *testing.T) {
+	config := "config_version: 1\n" +
+		"http:\n  address: x\n  prot: 80\n  tls:\n    cert: a\n    key: b\n" +
+		"cache:\n  ttl: 5s\n" +
+		"legacy: 1\n"
+
+	tests := []struct {
+		name string
+		read func(t *testing.T, cfg Configurer)
+		env  map[string]string
+		want []UnusedKey
+	}{
+		{
+			name: "nothing read",
+			want: []UnusedKey{
+				{Key: "cache.ttl"},
+				{Key: "http.address"},
+				{Key: "http.prot"},
+				{Key: "http.tls.cert"},
+				{Key: "http.tls.key"},
+				{Key: "legacy"},
+			},
+		},
+		{
+			name: "unmarshalled fields and suggestions",
+			read: func(t *testing.T, cfg Configurer) {
+				var http struct {
+					Address string `mapstructure:"address"`
+					Port    int    `mapstructure:"port"`
+					TLS     struct {
+						Cert string `mapstructure:"cert"`
+					} `mapstructure:"tls"`
+				}
+				if err := cfg.UnmarshalKey("http", &http); err != nil {
+					t.Fatal(err)
+				}
+				var cache struct {
+					Time string `mapstructure:"time"`
+				}
+				if err := cfg.UnmarshalKey("cache", &cache); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []UnusedKey{
+				// too far from `time` to be a typo
+				{Key: "cache.ttl"},
+				{Key: "http.prot", Suggestion: "http.port"},
+				// a key is suggested from its own section only
+				{Key: "http.tls.key"},
+				{Key: "legacy"},
+			},
+		},
+		{
+			name: "sections read by Get and Sub",
+			read: func(t *testing.T, cfg Configurer) {
+				_ = cfg.Get("http.tls")
+				_ = cfg.Sub("cache").Get("ttl")
+				_ = cfg.Get("legacy")
+			},
+			want: []UnusedKey{
+				{Key: "http.address"},
+				{Key: "http.prot"},
+			},
+		},
+		{
+			name: "environment is not reported",
+			read: func(t *testing.T, cfg Configurer) {
+				_ = cfg.Get("http")
+				_ = cfg.Get("cache")
+				_ = cfg.Get("legacy")
+			},
+			env: map[string]string{"APP_METRICS_PORT": "9090", "APP_LEGACY": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, err := NewConfigurer("v1",
+				WithConfigType("yaml"),
+				WithReadInCfg([]byte(config)),
+				WithPrefix("APP"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.read != nil {
+				tt.read(t, cfg)
+			}
+
+			var got []UnusedKey
+			for _, k := range cfg.UnusedKeys() {
+				got = append(got, UnusedKey{Key: k.Key, Suggestion: k.Suggestion})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnusedKeys() = %v, want %v", got, tt.want)
+			}
+
+			// the reads after UnusedKeys are not recorded
+			_ = cfg.Get("http")
+			if n := len(cfg.UnusedKeys()); n != len(tt.want) {
+				t.Errorf("UnusedKeys() after Get returned %d keys, want %d", n, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestUnusedKeyString(t *testing.T) {
+	key := UnusedKey{Key: "http.prot", Origin: Origin{Source: SourceFile, Location: "config.yaml"}, Suggestion: "http.port"}
+
+	want := "`http.prot` (file config.yaml), did you mean `http.port`?"
+	if got := key.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"port", "port", 0},
+		{"prot", "port", 1},
+		{"addr", "address", 3},
+		{"ttl", "time", 3},
+		{"", "key", 3},
+		{"ca", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
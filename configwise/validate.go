@@ -0,0 +1,411 @@
+package configwise
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/spf13/cast"
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+// Violation is a config value which does not match the schema.
+type Violation struct {
+	// Path is the dotted config key, empty for the root, list items are addressed by index, like `http.hosts.0`.
+	Path string
+	// File and Line point to the value for the file source, Line is zero if unknown.
+	File string
+	Line int
+	// Source describes where the value came from for the other sources, like `env APP_HTTP_PORT`.
+	Source  string
+	Message string
+}
+
+func (v Violation) String() string {
+	var loc string
+	switch {
+	case v.File != "" && v.Line > 0:
+		loc = fmt.Sprintf("%s:%d: ", v.File, v.Line)
+	case v.File != "":
+		loc = v.File + ": "
+	case v.Source != "":
+		loc = v.Source + ": "
+	}
+
+	path := v.Path
+	if path == "" {
+		path = "(root)"
+	}
+
+	return loc + path + ": " + v.Message
+}
+
+// SchemaError lists all the violations found by the schema validation.
+type SchemaError struct {
+	Violations []Violation
+}
+
+func (e *SchemaError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		lines = append(lines, "\n  "+v.String())
+	}
+	return "config does not match the schema:" + strings.Join(lines, "")
+}
+
+// WithSchema validates the merged config against the JSON Schema before publishing it,
+// NewConfigurer fails with SchemaError listing every violation. Use GenerateSchema
+// for the schema of the registered plugins or WithSchemaFile for a hand-written one.
+func WithSchema(schema *Schema) Option {
+	return func(c *configurer) {
+		c.schema = schema
+		c.schemaFile = ""
+	}
+}
+
+// WithSchemaFile is WithSchema with the JSON Schema read from the JSON or YAML file,
+// the draft of the schema is taken from its $schema keyword, 2020-12 if not set.
+func WithSchemaFile(file string) Option {
+	return func(c *configurer) {
+		c.schemaFile = file
+		c.schema = nil
+	}
+}
+
+// compileSchema compiles the schema of the options, nil if there is none.
+func (cfg *configurer) compileSchema() (*jsonschema.Schema, error) {
+	var (
+		url  = "config.schema.json"
+		data []byte
+		err  error
+	)
+
+	switch {
+	case cfg.schemaFile != "":
+		url = cfg.schemaFile
+		data, err = readSchemaFile(cfg.schemaFile)
+	case cfg.schema != nil:
+		data, err = json.Marshal(cfg.schema)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	if err = compiler.AddResource(url, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(url)
+}
+
+// readSchemaFile reads the schema file as JSON, YAML is a superset of JSON.
+func readSchemaFile(file string) ([]byte, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err = yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	if data, err = json.Marshal(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return data, nil
+}
+
+// validate checks the merged config against the schema.
+func (cfg *configurer) validate() error {
+	schema, err := cfg.compileSchema()
+	if err != nil || schema == nil {
+		return err
+	}
+
+	doc, err := jsonValue(cfg.coerce("", cfg.viper.AllSettings(), schema))
+	if err != nil {
+		return err
+	}
+
+	err = schema.Validate(doc)
+
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return err
+	}
+
+	violations := leafViolations(verr, nil)
+
+	lines := newLineIndex(cfg.fileData)
+	for i := range violations {
+		cfg.locate(&violations[i], lines)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Path < b.Path
+	})
+
+	return &SchemaError{Violations: violations}
+}
+
+// leafViolations collects the innermost errors, they point to the values which failed.
+func leafViolations(err *jsonschema.ValidationError, out []Violation) []Violation {
+	if len(err.Causes) == 0 {
+		v := Violation{Path: pointerPath(err.InstanceLocation), Message: err.Message}
+		if !slices.Contains(out, v) {
+			out = append(out, v)
+		}
+		return out
+	}
+
+	for _, cause := range err.Causes {
+		out = leafViolations(cause, out)
+	}
+	return out
+}
+
+// pointerPath converts the JSON pointer to the dotted key.
+func pointerPath(ptr string) string {
+	if ptr == "" {
+		return ""
+	}
+
+	keys := strings.Split(strings.TrimPrefix(ptr, "/"), "/")
+	for i, key := range keys {
+		keys[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(key)
+	}
+	return strings.Join(keys, ".")
+}
+
+// jsonValue converts the settings to the JSON values the validator accepts.
+func jsonValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var out interface{}
+	if err = dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// coerce converts the strings from env and flags to the types of the schema the way
+// the weakly typed decoding does, the values from files are validated as they are.
+func (cfg *configurer) coerce(path string, value interface{}, s *jsonschema.Schema) interface{} {
+	for s != nil && s.Ref != nil {
+		s = s.Ref
+	}
+	if s == nil {
+		return value
+	}
+
+	switch t := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for key, val := range t {
+			out[key] = cfg.coerce(joinPath(path, key), val, propertySchema(s, key))
+		}
+		return out
+	case []interface{}:
+		items := itemsSchema(s)
+		out := make([]interface{}, len(t))
+		for i, val := range t {
+			out[i] = cfg.coerce(joinPath(path, strconv.Itoa(i)), val, items)
+		}
+		return out
+	case string:
+		o, ok := cfg.origins[path]
+		if !ok || o.Source == SourceFile || o.Source == SourceDefault {
+			return value
+		}
+		for _, tp := range s.Types {
+			if converted, ok := weaklyTyped(tp, t); ok {
+				return converted
+			}
+		}
+	}
+	return value
+}
+
+func propertySchema(s *jsonschema.Schema, key string) *jsonschema.Schema {
+	if prop, ok := s.Properties[key]; ok {
+		return prop
+	}
+	prop, _ := s.AdditionalProperties.(*jsonschema.Schema)
+	return prop
+}
+
+func itemsSchema(s *jsonschema.Schema) *jsonschema.Schema {
+	if s.Items2020 != nil {
+		return s.Items2020
+	}
+	items, _ := s.Items.(*jsonschema.Schema)
+	return items
+}
+
+// weaklyTyped converts the string to the JSON type.
+func weaklyTyped(tp, str string) (interface{}, bool) {
+	switch tp {
+	case "string":
+		return str, true
+	case "boolean":
+		b, err := cast.ToBoolE(str)
+		return b, err == nil
+	case "integer":
+		n, err := cast.ToInt64E(str)
+		return n, err == nil
+	case "number":
+		n, err := cast.ToFloat64E(str)
+		return n, err == nil
+	case "array":
+		items := strings.Split(str, ",")
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			out[i] = item
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// locate fills the source of the violation, the value of a missing key is looked up by its section.
+func (cfg *configurer) locate(v *Violation, lines *lineIndex) {
+	for path := v.Path; ; path = path[:strings.LastIndexByte(path, '.')] {
+		if o, ok := cfg.origins[path]; ok {
+			if o.Source != SourceFile {
+				v.Source = o.String()
+				return
+			}
+			v.File = o.Location
+			v.Line = lines.line(v.File, path)
+			return
+		}
+
+		if file := cfg.sectionFile(path); file != "" {
+			v.File = file
+			v.Line = lines.line(v.File, path)
+			return
+		}
+
+		if !strings.Contains(path, ".") {
+			return
+		}
+	}
+}
+
+// sectionFile returns the file of the first value of the section.
+func (cfg *configurer) sectionFile(path string) string {
+	var keys []string
+	for key, o := range cfg.origins {
+		if o.Source == SourceFile && strings.HasPrefix(key, path+".") {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return ""
+	}
+
+	sort.Strings(keys)
+	return cfg.origins[keys[0]].Location
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// lineIndex finds the lines of the dotted keys in the YAML and JSON files.
+type lineIndex struct {
+	docs map[string]*yaml.Node
+	// read returns the parsed content of the file
+	read func(file string) ([]byte, error)
+}
+
+func newLineIndex(read func(file string) ([]byte, error)) *lineIndex {
+	return &lineIndex{docs: make(map[string]*yaml.Node), read: read}
+}
+
+func (l *lineIndex) line(file, path string) int {
+	doc, ok := l.docs[file]
+	if !ok {
+		doc = &yaml.Node{}
+		switch strings.TrimPrefix(filepath.Ext(file), ".") {
+		case "yaml", "yml", "json":
+			if data, err := l.read(file); err == nil {
+				_ = yaml.Unmarshal(data, doc)
+			}
+		}
+		l.docs[file] = doc
+	}
+
+	node := doc
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	line := 0
+	for _, key := range strings.Split(path, ".") {
+		next, keyLine := child(node, key)
+		if next == nil {
+			break
+		}
+		node, line = next, keyLine
+	}
+	return line
+}
+
+// child returns the value of the mapping key or the sequence item and the line of the key.
+func child(node *yaml.Node, key string) (*yaml.Node, int) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if strings.EqualFold(node.Content[i].Value, key) {
+				return node.Content[i+1], node.Content[i].Line
+			}
+		}
+	case yaml.SequenceNode:
+		i, err := cast.ToIntE(key)
+		if err == nil && i >= 0 && i < len(node.Content) {
+			return node.Content[i], node.Content[i].Line
+		}
+	}
+	return nil, 0
+}
+
+// fileData returns the content of the config file the way it was parsed,
+// the templates are rendered.
+func (cfg *configurer) fileData(file string) ([]byte, error) {
+	if data, ok := cfg.rendered[file]; ok {
+		return data, nil
+	}
+	return os.ReadFile(file)
+}
@@ -0,0 +1,202 @@
+package configwise
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		config string
+		// violations are the expected "path: message substring" pairs
+		violations [][2]string
+		err        string
+	}{
+		{
+			name:   "additional properties false",
+			schema: `{"type":"object","properties":{"http":{"type":"object","properties":{"address":{"type":"string"}},"additionalProperties":false}}}`,
+			config: "http:\n  address: x\n  port: 80\n",
+			violations: [][2]string{
+				{"http", "port"},
+			},
+		},
+		{
+			name:   "additional properties true",
+			schema: `{"type":"object","additionalProperties":true}`,
+			config: "http:\n  port: 80\n",
+		},
+		{
+			name:   "false property",
+			schema: "properties:\n  legacy: false\n",
+			config: "legacy: 1\n",
+			violations: [][2]string{
+				{"legacy", "not allowed"},
+			},
+		},
+		{
+			name:   "type list",
+			schema: `{"properties":{"a":{"type":["string","null"]},"b":{"type":["string","null"]},"c":{"type":["string","null"]}}}`,
+			config: "a: x\nb: null\nc: 1\n",
+			violations: [][2]string{
+				{"c", "expected string or null"},
+			},
+		},
+		{
+			name:   "references and array keywords",
+			schema: `{"$defs":{"hosts":{"type":"array","minItems":1,"items":{"type":"string"}}},"properties":{"hosts":{"$ref":"#/$defs/hosts"}}}`,
+			config: "hosts: []\n",
+			violations: [][2]string{
+				{"hosts", "minimum 1 items"},
+			},
+		},
+		{
+			name:   "every violation",
+			schema: `{"properties":{"a":{"type":"integer"},"b":{"type":"integer","maximum":10}}}`,
+			config: "a: x\nb: 11\n",
+			violations: [][2]string{
+				{"a", "expected integer"},
+				{"b", "must be <= 10"},
+			},
+		},
+		{
+			name:   "invalid schema",
+			schema: `{"type":1}`,
+			err:    "compilation failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			schema := filepath.Join(dir, "schema.json")
+			if err := os.WriteFile(schema, []byte(tt.schema), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			file := filepath.Join(dir, "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := NewConfigurer("v1", WithPaths(file), WithPrefix("APP"), WithSchemaFile(schema))
+
+			var serr *SchemaError
+			if tt.err != "" {
+				if err == nil || errors.As(err, &serr) || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("NewConfigurer() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+
+			if !errors.As(err, &serr) {
+				if err != nil || len(tt.violations) > 0 {
+					t.Fatalf("NewConfigurer() error = %v, want %d violations", err, len(tt.violations))
+				}
+				return
+			}
+
+			if len(serr.Violations) != len(tt.violations) {
+				t.Fatalf("violations = %v, want %q", serr.Violations, tt.violations)
+			}
+			for i, want := range tt.violations {
+				if got := serr.Violations[i]; got.Path != want[0] || !strings.Contains(got.Message, want[1]) {
+					t.Errorf("violation = %s: %s, want %s: ...%s...", got.Path, got.Message, want[0], want[1])
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaViolationSource(t *testing.T) {
+	schema := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"http": {
+				Type: "object",
+				Properties: map[string]*Schema{
+					"port":  {Type: "integer"},
+					"debug": {Type: "boolean"},
+					"hosts": {Type: "array", Items: &Schema{Type: "string"}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		config   string
+		template bool
+		env      map[string]string
+		// want is the violation, empty if the config is valid
+		want Violation
+	}{
+		{
+			name:   "file line",
+			config: "http:\n  debug: true\n  port: x\n",
+			want:   Violation{Path: "http.port", File: "config.yaml", Line: 3},
+		},
+		{
+			name:     "rendered template line",
+			config:   "{{- /*\n  the comment\n  is not rendered\n*/ -}}\nhttp:\n  port: x\n",
+			template: true,
+			want:     Violation{Path: "http.port", File: "config.yaml", Line: 2},
+		},
+		{
+			name:   "env values are converted",
+			config: "http:\n  port: 80\n  debug: false\n  hosts: [a]\n",
+			env:    map[string]string{"APP_HTTP_PORT": "8080", "APP_HTTP_DEBUG": "true", "APP_HTTP_HOSTS": "a,b"},
+		},
+		{
+			name:   "env source",
+			config: "http:\n  port: 80\n",
+			env:    map[string]string{"APP_HTTP_PORT": "x"},
+			want:   Violation{Path: "http.port", Source: "env APP_HTTP_PORT"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := []Option{WithPaths(file), WithPrefix("APP"), WithSchema(schema)}
+			if tt.template {
+				opts = append(opts, WithTemplate())
+			}
+
+			_, err := NewConfigurer("v1", opts...)
+
+			if tt.want.Path == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var serr *SchemaError
+			if !errors.As(err, &serr) || len(serr.Violations) != 1 {
+				t.Fatalf("NewConfigurer() error = %v, want one violation", err)
+			}
+
+			got := serr.Violations[0]
+			if got.File != "" {
+				got.File = filepath.Base(got.File)
+			}
+			if got.Path != tt.want.Path || got.File != tt.want.File || got.Line != tt.want.Line || got.Source != tt.want.Source {
+				t.Errorf("violation = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
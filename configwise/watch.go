@@ -0,0 +1,67 @@
+package configwise
+
+import (
+	"reflect"
+)
+
+type watcher struct {
+	prefix string
+	fn     func(old, new interface{})
+}
+
+func (cfg *configurer) Watch(prefix string, fn func(old, new interface{})) func() {
+	cfg.watchMu.Lock()
+	defer cfg.watchMu.Unlock()
+
+	if cfg.watchers == nil {
+		cfg.watchers = make(map[uint64]*watcher)
+	}
+
+	cfg.watchID++
+	id := cfg.watchID
+	cfg.watchers[id] = &watcher{prefix: normalizeKey(prefix), fn: fn}
+
+	return func() {
+		cfg.watchMu.Lock()
+		defer cfg.watchMu.Unlock()
+
+		delete(cfg.watchers, id)
+	}
+}
+
+// swap replaces the current snapshot and returns the previous one, the caller holds mu.
+func (cfg *configurer) swap(next *snapshot) *snapshot {
+	return cfg.snap.Swap(next)
+}
+
+// notify calls the watchers whose values differ between the snapshots,
+// the caller must not hold mu, so a watcher is free to change the config.
+func (cfg *configurer) notify(prev, next *snapshot) {
+	cfg.watchMu.Lock()
+	watchers := make([]*watcher, 0, len(cfg.watchers))
+	for _, w := range cfg.watchers {
+		watchers = append(watchers, w)
+	}
+	cfg.watchMu.Unlock()
+
+	for _, w := range watchers {
+		oldVal, newVal := prev.value(w.prefix), next.value(w.prefix)
+		if !reflect.DeepEqual(oldVal, newVal) {
+			w.fn(oldVal, newVal)
+		}
+	}
+}
+
+// value returns the value at the key, empty key is the whole config.
+// It reads viper directly, watching a key doesn't make it used.
+func (s *snapshot) value(key string) interface{} {
+	if key == "" {
+		return s.viper.AllSettings()
+	}
+	if !s.viper.IsSet(key) {
+		if val, ok := s.cfg.lookupEnv(key); ok {
+			return val
+		}
+	}
+	return s.viper.Get(key)
+}
@@ -0,0 +1,76 @@
+package configwise
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchOverwriteInCallback(t *testing.T) {
+	cfg, err := NewConfigurer("v1",
+		WithConfigType("yaml"),
+		WithReadInCfg([]byte("http:\n  port: 80\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var calls []interface{}
+	cancel := cfg.Watch("http.port", func(_, newVal interface{}) {
+		calls = append(calls, newVal)
+		// the follow-up write is seen by the watcher as well
+		if newVal == 8080 {
+			if err := cfg.Overwrite(map[string]interface{}{"http.port": 9090}); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- cfg.Overwrite(map[string]interface{}{"http.port": 8080}) }()
+
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Overwrite from the watcher deadlocked")
+	}
+
+	if len(calls) != 2 || calls[0] != 8080 || calls[1] != 9090 {
+		t.Errorf("watcher calls = %v, want [8080 9090]", calls)
+	}
+	if got := cfg.Get("http.port"); got != 9090 {
+		t.Errorf("Get(http.port) = %v, want 9090", got)
+	}
+}
+
+func TestWatchDoesNotUseKeys(t *testing.T) {
+	cfg, err := NewConfigurer("v1",
+		WithConfigType("yaml"),
+		WithReadInCfg([]byte("http:\n  port: 80\ncache:\n  ttl: 5s\n")),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel := cfg.Watch("", func(_, _ interface{}) {})
+	defer cancel()
+	cancelPort := cfg.Watch("http.port", func(_, _ interface{}) {})
+	defer cancelPort()
+
+	if err = cfg.Overwrite(map[string]interface{}{"cache.size": 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	unused := map[string]bool{}
+	for _, k := range cfg.UnusedKeys() {
+		unused[k.Key] = true
+	}
+	for _, key := range []string{"http.port", "cache.ttl"} {
+		if !unused[key] {
+			t.Errorf("UnusedKeys() has no %s, got %v", key, unused)
+		}
+	}
+}
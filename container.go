@@ -7,7 +7,9 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"syscall"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/roadrunner-server/endure/v2"
 	rrErrs "github.com/roadrunner-server/errors"
@@ -24,8 +26,18 @@ var ErrContainerNotFound = errors.New("container not found in context, call With
 type Container struct {
 	inner   *endure.Endure
 	cfg     configwise.Configurer
+	conf    *Config
 	log     *slog.Logger
 	plugins []interface{}
+	ready   atomic.Bool
+
+	mu        sync.RWMutex
+	infos     []PluginInfo
+	active    []string
+	state     State
+	startedAt time.Time
+	stoppedAt time.Time
+	lastErr   error
 }
 
 type containerKey struct{}
@@ -35,18 +47,28 @@ func init() {
 }
 
 func NewContainer(cfg configwise.Configurer, log logwise.Logger) *Container {
-	return &Container{
+	c := &Container{
 		cfg: cfg,
 		log: log.NamedLogger(endureKey),
-		plugins: []interface{}{
-			&configwise.Plugin{Cfg: cfg},
-			&logwise.Plugin{Log: log},
-		},
 	}
+
+	c.RegisterAll(
+		&configwise.Plugin{Cfg: cfg},
+		&logwise.Plugin{Log: log},
+	)
+
+	return c
 }
 
 func (c *Container) RegisterAll(plugins ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.plugins = append(c.plugins, plugins...)
+
+	for _, plugin := range plugins {
+		c.infos = append(c.infos, describePlugin(plugin))
+	}
 }
 
 func (c *Container) Init() error {
@@ -54,31 +76,79 @@ func (c *Container) Init() error {
 
 	cfg, err := NewConfig(c.cfg, endureKey)
 	if err != nil {
-		return rrErrs.E(op, err)
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return err
 	}
 
+	c.conf = cfg
 	c.cfg.SetGracefulTimeout(cfg.GracePeriod)
 
+	var (
+		level   slog.Leveler = slog.LevelError
+		handler              = c.log.Handler()
+	)
+
+	if cfg.LogLevel != "" {
+		level = logwise.ToLeveler(cfg.LogLevel)
+		handler = logwise.NewLevelHandler(level, handler)
+	}
+
 	opts := []endure.Options{
 		endure.GracefulShutdownTimeout(cfg.GracePeriod),
-		endure.LogHandler(c.log.Handler()),
+		endure.LogHandler(handler),
 	}
 
 	if cfg.PrintGraph {
 		opts = append(opts, endure.Visualize())
 	}
 
-	c.inner = endure.New(slog.LevelError, opts...)
+	if cfg.Profiler {
+		opts = append(opts, endure.EnableProfiler())
+	}
+
+	c.inner = endure.New(level, opts...)
 
-	if err = c.inner.RegisterAll(c.plugins...); err != nil {
-		return rrErrs.E(op, err)
+	if err = c.inner.RegisterAll(c.registered()...); err != nil {
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return err
 	}
 
-	if err = c.inner.Init(); err != nil {
+	if err = c.withStartupTimeout("init", c.inner.Init); err != nil {
 		if errs.IsSuccess(err) {
+			c.setState(StateStopped, nil)
 			return err
 		}
-		return rrErrs.E(op, err)
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return err
+	}
+
+	if err = c.checkUnusedKeys(); err != nil {
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return err
+	}
+
+	c.setState(StateInitialized, nil)
+
+	return nil
+}
+
+// checkUnusedKeys reports the config keys the plugins did not read during Init.
+func (c *Container) checkUnusedKeys() error {
+	unused := c.cfg.UnusedKeys()
+	if len(unused) == 0 {
+		return nil
+	}
+
+	if c.conf.StrictConfig {
+		return &configwise.UnusedKeysError{Keys: unused}
+	}
+
+	for _, key := range unused {
+		c.log.Warn("unknown config key " + key.String())
 	}
 	return nil
 }
@@ -86,22 +156,42 @@ func (c *Container) Init() error {
 func (c *Container) Serve() (<-chan *endure.Result, error) {
 	const op = rrErrs.Op("container_run")
 
-	errCh, err := c.inner.Serve()
+	var errCh <-chan *endure.Result
+
+	err := c.withStartupTimeout("serve", func() (err error) {
+		errCh, err = c.inner.Serve()
+		return
+	})
 	if err != nil {
 		if errs.IsSuccess(err) {
+			c.setState(StateStopped, nil)
 			return nil, err
 		}
-		return nil, rrErrs.E(op, err)
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return nil, err
 	}
+
+	c.setState(StateServing, nil)
+	c.ready.Store(true)
+
 	return errCh, nil
 }
 
 func (c *Container) Stop() error {
 	const op = rrErrs.Op("container_stop")
 
+	c.ready.Store(false)
+	c.setState(StateStopping, nil)
+
 	if err := c.inner.Stop(); err != nil {
-		return rrErrs.E(op, err)
+		err = rrErrs.E(op, err)
+		c.setState(StateFailed, err)
+		return err
 	}
+
+	c.setState(StateStopped, nil)
+
 	return nil
 }
 
@@ -115,15 +205,26 @@ func (c *Container) Run() error {
 		return errs.Go(err)
 	}
 
+	signals, err := c.conf.OSSignals()
+	if err != nil {
+		return errs.Append(err, c.Stop())
+	}
+
 	oss, stop := make(chan os.Signal, 5), make(chan struct{}, 1) //nolint:gomnd
-	signal.Notify(oss, os.Interrupt, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(oss, signals...)
 
 	go func() {
 		<-oss
 
 		stop <- struct{}{}
 
-		<-oss
+		if !c.conf.ForceExit {
+			return
+		}
+
+		for i := 0; i < c.conf.ForceExitSignals; i++ {
+			<-oss
+		}
 		c.log.Info("exit forced")
 		os.Exit(1)
 	}()
@@ -136,12 +237,24 @@ func (c *Container) Run() error {
 			}
 
 			err1 := fmt.Errorf("plugin: %s. %w", e.VertexID, e.Error)
+			c.setState(StateFailed, err1)
+
 			err2 := c.Stop()
 
 			return errs.Append(err1, err2)
 		case <-stop:
 			c.log.Info(fmt.Sprintf("stop signal received, grace timeout is: %0.f seconds", c.cfg.GracefulTimeout().Seconds()))
 
+			if c.conf.KillTimeout > 0 {
+				kill := time.AfterFunc(c.conf.KillTimeout, func() {
+					c.log.Info("kill timeout exceeded, exit forced")
+					os.Exit(1)
+				})
+				defer kill.Stop()
+			}
+
+			c.drain()
+
 			return c.Stop()
 		}
 	}
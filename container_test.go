@@ -0,0 +1,120 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rumorshub/ioc/configwise"
+	"github.com/rumorshub/ioc/logwise"
+)
+
+// newTestContainer creates the container with the YAML config, the logs go to a file in the test directory.
+func newTestContainer(t *testing.T, config string, plugins ...interface{}) *Container {
+	t.Helper()
+
+	cfg, err := configwise.NewConfigurer("v1",
+		configwise.WithConfigType("yaml"),
+		configwise.WithReadInCfg([]byte(config)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := logwise.NewLogger(logwise.Config{
+		Level:       "debug",
+		Encoding:    "json",
+		OutputPaths: []string{filepath.Join(t.TempDir(), "test.log")},
+	}, logwise.ChannelConfig{})
+
+	c := NewContainer(cfg, log)
+	c.RegisterAll(plugins...)
+
+	return c
+}
+
+// servicePlugin is a plugin doing nothing.
+type servicePlugin struct{}
+
+func (p *servicePlugin) Init() error                { return nil }
+func (p *servicePlugin) Serve() chan error          { return make(chan error, 1) }
+func (p *servicePlugin) Stop(context.Context) error { return nil }
+func (p *servicePlugin) Name() string               { return "service" }
+
+// httpPlugin reads the http section.
+type httpPlugin struct {
+	servicePlugin
+}
+
+func (p *httpPlugin) Init(cfg configwise.Configurer) error {
+	var conf struct {
+		Port int `mapstructure:"port"`
+	}
+	return cfg.UnmarshalKey("http", &conf)
+}
+
+func (p *httpPlugin) Name() string { return "http" }
+
+func TestUnusedConfigKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		strict bool
+	}{
+		{name: "warning", config: "http:\n  prot: 80\n"},
+		{name: "strict", config: "endure:\n  strict_config: true\nhttp:\n  prot: 80\n", strict: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := configwise.NewConfigurer("v1",
+				configwise.WithConfigType("yaml"),
+				configwise.WithReadInCfg([]byte(tt.config)),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logFile := filepath.Join(t.TempDir(), "test.log")
+			log := logwise.NewLogger(logwise.Config{
+				Level:       "debug",
+				Encoding:    "json",
+				OutputPaths: []string{logFile},
+			}, logwise.ChannelConfig{})
+
+			c := NewContainer(cfg, log)
+			c.RegisterAll(&httpPlugin{})
+
+			err = c.Init()
+
+			if tt.strict {
+				var uerr *configwise.UnusedKeysError
+				if !errors.As(unwrapOp(err), &uerr) {
+					t.Fatalf("Init() error = %v, want UnusedKeysError", err)
+				}
+				if len(uerr.Keys) != 1 || uerr.Keys[0].Key != "http.prot" || uerr.Keys[0].Suggestion != "http.port" {
+					t.Errorf("UnusedKeysError.Keys = %v, want http.prot with http.port suggested", uerr.Keys)
+				}
+				if state := c.Info().State; state != StateFailed {
+					t.Errorf("State = %s, want %s", state, StateFailed)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(logFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "did you mean `http.port`?") {
+				t.Errorf("log has no warning about http.prot:\n%s", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+
+	"github.com/roadrunner-server/endure/v2"
+	"golang.org/x/exp/slices"
+)
+
+// Drainer is implemented by plugins which should stop accepting new work
+// (close listeners, leave the load balancer pool, etc.) before the container
+// is stopped. Drain is called once the container is not ready anymore, the
+// context is cancelled when the drain period is over.
+type Drainer interface {
+	Drain(ctx context.Context) error
+}
+
+// Ready reports whether the container is serving and not draining.
+func (c *Container) Ready() bool {
+	return c.ready.Load()
+}
+
+func (c *Container) drain() {
+	c.ready.Store(false)
+	c.setState(StateDraining, nil)
+
+	if c.conf == nil || c.conf.DrainPeriod <= 0 {
+		return
+	}
+
+	c.log.Info(fmt.Sprintf("draining, drain period is: %0.f seconds", c.conf.DrainPeriod.Seconds()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.conf.DrainPeriod)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, plugin := range c.registered() {
+		drainer, ok := plugin.(Drainer)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(plugin interface{}, drainer Drainer) {
+			defer wg.Done()
+
+			if err := drainer.Drain(ctx); err != nil {
+				c.log.Warn("drain failed", slog.String("plugin", pluginName(plugin)), slog.Any("error", err))
+			}
+		}(plugin, drainer)
+	}
+	wg.Wait()
+
+	// keep reporting not ready until the end of the period,
+	// so load balancers have time to notice it
+	<-ctx.Done()
+}
+
+// registered returns a copy of the registered plugins, it's safe for concurrent use.
+func (c *Container) registered() []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return slices.Clone(c.plugins)
+}
+
+func pluginName(plugin interface{}) string {
+	if named, ok := plugin.(endure.Named); ok {
+		return named.Name()
+	}
+	return reflect.TypeOf(plugin).String()
+}
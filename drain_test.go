@@ -0,0 +1,119 @@
+package ioc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type drainPlugin struct {
+	servicePlugin
+
+	c *Container
+
+	mu          sync.Mutex
+	drained     bool
+	readyInside bool
+	hasDeadline bool
+}
+
+func (p *drainPlugin) Name() string { return "drainer" }
+
+func (p *drainPlugin) Drain(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.drained = true
+	p.readyInside = p.c.Ready()
+	_, p.hasDeadline = ctx.Deadline()
+
+	return nil
+}
+
+func TestDrain(t *testing.T) {
+	const period = 100 * time.Millisecond
+
+	p := &drainPlugin{}
+	c := newTestContainer(t, "endure:\n  drain_period: 100ms\n", p)
+	p.c = c
+
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.Ready() {
+		t.Fatal("Ready() = false after Serve")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// registering while draining must not race with it
+		c.RegisterAll(&servicePlugin{})
+	}()
+
+	start := time.Now()
+	c.drain()
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < period {
+		t.Errorf("drain returned after %s, want at least %s", elapsed, period)
+	}
+
+	p.mu.Lock()
+	if !p.drained {
+		t.Error("Drain was not called")
+	}
+	if p.readyInside {
+		t.Error("Ready() = true inside Drain")
+	}
+	if !p.hasDeadline {
+		t.Error("Drain context has no deadline")
+	}
+	p.mu.Unlock()
+
+	if got := c.Info().State; got != StateDraining {
+		t.Errorf("State = %s, want %s", got, StateDraining)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Ready() {
+		t.Error("Ready() = true after Stop")
+	}
+	if got := c.Info().State; got != StateStopped {
+		t.Errorf("State = %s, want %s", got, StateStopped)
+	}
+}
+
+func TestDrainWithoutPeriod(t *testing.T) {
+	p := &drainPlugin{}
+	c := newTestContainer(t, "", p)
+	p.c = c
+
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	c.drain()
+
+	if c.Ready() {
+		t.Error("Ready() = true after drain")
+	}
+	if p.drained {
+		t.Error("Drain was called without drain period")
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
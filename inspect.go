@@ -0,0 +1,150 @@
+package ioc
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/roadrunner-server/endure/v2"
+	"golang.org/x/exp/slices"
+)
+
+// State is the container lifecycle state.
+type State int
+
+const (
+	StateNew State = iota
+	StateInitialized
+	StateServing
+	StateDraining
+	StateStopping
+	StateStopped
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateInitialized:
+		return "initialized"
+	case StateServing:
+		return "serving"
+	case StateDraining:
+		return "draining"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// PluginInfo describes a registered plugin.
+type PluginInfo struct {
+	Name string
+	Type string
+	// Active is false when endure disabled the plugin (not enough dependencies)
+	// or the container is not initialized yet.
+	Active bool
+	// Provides lists the types from the Provides method.
+	Provides []string
+	// Depends lists the types from the Init arguments and the Collects method.
+	Depends []string
+}
+
+// Info is a point in time view of the container.
+type Info struct {
+	State     State
+	StartedAt time.Time
+	// StoppedAt is the time the container left the serving state, zero while serving.
+	StoppedAt time.Time
+	// Uptime is frozen once the container stops serving.
+	Uptime    time.Duration
+	LastError error
+	Plugins   []PluginInfo
+}
+
+// Info returns the container introspection data, it's safe for concurrent use.
+func (c *Container) Info() Info {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	info := Info{
+		State:     c.state,
+		StartedAt: c.startedAt,
+		StoppedAt: c.stoppedAt,
+		LastError: c.lastErr,
+		Plugins:   make([]PluginInfo, len(c.infos)),
+	}
+
+	switch {
+	case c.startedAt.IsZero():
+	case c.stoppedAt.IsZero():
+		info.Uptime = time.Since(c.startedAt)
+	default:
+		info.Uptime = c.stoppedAt.Sub(c.startedAt)
+	}
+
+	for i, p := range c.infos {
+		p.Active = slices.Contains(c.active, p.Name)
+		p.Provides = slices.Clone(p.Provides)
+		p.Depends = slices.Clone(p.Depends)
+		info.Plugins[i] = p
+	}
+
+	return info
+}
+
+func (c *Container) setState(state State, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state = state
+	if err != nil {
+		c.lastErr = err
+	}
+
+	switch state {
+	case StateInitialized:
+		c.active = c.inner.Plugins()
+	case StateServing:
+		c.startedAt = time.Now()
+		c.stoppedAt = time.Time{}
+	case StateStopping, StateStopped, StateFailed:
+		if !c.startedAt.IsZero() && c.stoppedAt.IsZero() {
+			c.stoppedAt = time.Now()
+		}
+	}
+}
+
+func describePlugin(plugin interface{}) PluginInfo {
+	info := PluginInfo{
+		Name: pluginName(plugin),
+		Type: reflect.TypeOf(plugin).String(),
+	}
+
+	if provider, ok := plugin.(endure.Provider); ok {
+		for _, out := range provider.Provides() {
+			info.Provides = append(info.Provides, out.Type.String())
+		}
+	}
+
+	if init, ok := reflect.TypeOf(plugin).MethodByName(endure.InitMethodName); ok {
+		// skip receiver
+		for i := 1; i < init.Type.NumIn(); i++ {
+			info.Depends = append(info.Depends, init.Type.In(i).String())
+		}
+	}
+
+	if collector, ok := plugin.(endure.Collector); ok {
+		for _, in := range collector.Collects() {
+			info.Depends = append(info.Depends, in.Type.String())
+		}
+	}
+
+	return info
+}
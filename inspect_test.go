@@ -0,0 +1,126 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/rumorshub/ioc/configwise"
+)
+
+// dependentPlugin depends on the configurer.
+type dependentPlugin struct {
+	servicePlugin
+}
+
+func (p *dependentPlugin) Init(configwise.Configurer) error { return nil }
+func (p *dependentPlugin) Name() string                     { return "dependent" }
+
+// failingPlugin fails to initialize.
+type failingPlugin struct {
+	servicePlugin
+}
+
+func (p *failingPlugin) Init() error  { return errors.New("init failed") }
+func (p *failingPlugin) Name() string { return "failing" }
+
+func TestInfoStates(t *testing.T) {
+	c := newTestContainer(t, "", &dependentPlugin{})
+
+	info := c.Info()
+	if info.State != StateNew || !info.StartedAt.IsZero() || info.Uptime != 0 {
+		t.Errorf("new Info() = %+v", info)
+	}
+
+	i := slices.IndexFunc(info.Plugins, func(p PluginInfo) bool { return p.Name == "dependent" })
+	if i < 0 {
+		t.Fatalf("Info().Plugins has no dependent plugin: %+v", info.Plugins)
+	}
+	if p := info.Plugins[i]; p.Active || p.Type != "*ioc.dependentPlugin" || !slices.Contains(p.Depends, "configwise.Configurer") {
+		t.Errorf("dependent plugin info = %+v", p)
+	}
+
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+
+	info = c.Info()
+	if info.State != StateInitialized {
+		t.Errorf("State = %s, want %s", info.State, StateInitialized)
+	}
+	for _, p := range info.Plugins {
+		if !p.Active {
+			t.Errorf("plugin %s is not active after Init", p.Name)
+		}
+	}
+
+	if _, err := c.Serve(); err != nil {
+		t.Fatal(err)
+	}
+
+	info = c.Info()
+	if info.State != StateServing || info.StartedAt.IsZero() || !info.StoppedAt.IsZero() {
+		t.Errorf("serving Info() = %+v", info)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if uptime := c.Info().Uptime; uptime < 20*time.Millisecond {
+		t.Errorf("Uptime = %s while serving, want at least 20ms", uptime)
+	}
+
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	info = c.Info()
+	if info.State != StateStopped || info.StoppedAt.IsZero() || info.LastError != nil {
+		t.Errorf("stopped Info() = %+v", info)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if uptime := c.Info().Uptime; uptime != info.Uptime {
+		t.Errorf("Uptime = %s after stop, want frozen %s", uptime, info.Uptime)
+	}
+}
+
+func TestInfoFailed(t *testing.T) {
+	c := newTestContainer(t, "", &failingPlugin{})
+
+	if err := c.Init(); err == nil {
+		t.Fatal("Init() succeeded")
+	}
+
+	info := c.Info()
+	if info.State != StateFailed || info.LastError == nil {
+		t.Errorf("Info() = %s/%v, want failed with the error", info.State, info.LastError)
+	}
+	if !info.StartedAt.IsZero() || !info.StoppedAt.IsZero() || info.Uptime != 0 {
+		t.Errorf("Info() of the container which never served = %+v", info)
+	}
+}
+
+func TestInfoConcurrent(t *testing.T) {
+	c := newTestContainer(t, "", &servicePlugin{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		for ctx.Err() == nil {
+			_ = c.Info()
+		}
+	}()
+
+	if err := c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Serve(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Stop(); err != nil {
+		t.Fatal(err)
+	}
+}
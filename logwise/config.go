@@ -10,7 +10,7 @@ import (
 // ChannelConfig configures loggers per channel.
 type ChannelConfig struct {
 	// Dedicated channels per logger. By default logger allocated via named logger.
-	Channels map[string]Config `mapstructure:"channels" json:"channels,omitempty" bson:"channels,omitempty"`
+	Channels map[string]Config `mapstructure:"channels" doc:"dedicated loggers by channel name" json:"channels,omitempty" bson:"channels,omitempty"`
 }
 
 type Config struct {
@@ -18,24 +18,34 @@ type Config struct {
 	// attribute to the output indicating the source code position of the log
 	// statement. AddSource is false by default to skip the cost of computing
 	// this information.
-	AddSource bool `mapstructure:"add_source" json:"add_source,omitempty" bson:"add_source,omitempty"`
+	AddSource bool `mapstructure:"add_source" doc:"add the source code position of the log statement" json:"add_source,omitempty" bson:"add_source,omitempty"`
 
 	// Level is the minimum enabled logging level.
-	Level string `mapstructure:"level" json:"level,omitempty" bson:"level,omitempty"`
+	Level string `mapstructure:"level" doc:"minimum enabled logging level" enum:"debug,info,warn,error" json:"level,omitempty" bson:"level,omitempty"`
 
 	// Encoding sets the logger's encoding. Init values are "json", "text" and "console"
-	Encoding string `mapstructure:"encoding" json:"encoding,omitempty" bson:"encoding,omitempty"`
+	Encoding string `mapstructure:"encoding" doc:"output encoding" enum:"json,text,console" json:"encoding,omitempty" bson:"encoding,omitempty"`
 
 	// Output is a list of URLs or file paths to write logging output to.
 	// See Open for details.
-	OutputPaths []string `mapstructure:"output_paths" json:"output_paths,omitempty" bson:"output_paths,omitempty"`
+	OutputPaths []string `mapstructure:"output_paths" doc:"URLs or file paths to write logging output to" json:"output_paths,omitempty" bson:"output_paths,omitempty"`
 
-	Attrs map[string]any `mapstructure:"attributes" json:"attrs,omitempty" bson:"attrs,omitempty"`
+	Attrs map[string]any `mapstructure:"attributes" doc:"attributes added to every record" json:"attrs,omitempty" bson:"attrs,omitempty"`
+}
+
+// defaultConfig returns the defaults of the log section, channels without
+// the output paths write to the default output.
+func defaultConfig() Config {
+	return Config{
+		Level:       "info",
+		Encoding:    "json",
+		OutputPaths: []string{"stderr"},
+	}
 }
 
 func (cfg *Config) OpenSinks() (zapcore.WriteSyncer, error) {
 	if len(cfg.OutputPaths) == 0 {
-		cfg.OutputPaths = []string{"stderr"}
+		cfg.OutputPaths = defaultConfig().OutputPaths
 	}
 
 	sink, _, err := zap.Open(cfg.OutputPaths...)
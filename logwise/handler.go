@@ -226,6 +226,28 @@ func (h *handlerSyncer) Sync() (err error) {
 	return
 }
 
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+}
+
+// NewLevelHandler returns a handler which overrides the minimum level of the inner one.
+func NewLevelHandler(level slog.Leveler, inner slog.Handler) slog.Handler {
+	return &levelHandler{Handler: inner, level: level}
+}
+
+func (h *levelHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return NewLevelHandler(h.level, h.Handler.WithAttrs(attrs))
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return NewLevelHandler(h.level, h.Handler.WithGroup(name))
+}
+
 type xHandlerWrapper struct {
 	inner slog.Handler
 }
@@ -112,6 +112,14 @@ func (cfg *Config) Logger(attrs ...slog.Attr) (*slog.Logger, error) {
 	return slog.New(NewHandlerSyncer(syncer, handler)), nil
 }
 
+func init() {
+	configwise.RegisterDefaults(PluginName, defaultConfig())
+	configwise.RegisterSchema(PluginName, struct {
+		Config        `mapstructure:",squash"`
+		ChannelConfig `mapstructure:",squash"`
+	}{})
+}
+
 func NewChannelConfig(cfg configwise.Configurer, key string) (c ChannelConfig, err error) {
 	if cfg.Has(key) {
 		err = cfg.UnmarshalKey(key, &c)
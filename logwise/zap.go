@@ -28,7 +28,7 @@ func (c *ZapCore) Enabled(lvl zapcore.Level) bool {
 }
 
 func (c *ZapCore) With(fields []zapcore.Field) zapcore.Core {
-	return &ZapCore{log: c.log.With(toSlogFields(fields))}
+	return &ZapCore{log: slog.New(c.log.Handler().WithAttrs(toSlogFields(fields)))}
 }
 
 func (c *ZapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
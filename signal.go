@@ -0,0 +1,41 @@
+package ioc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+)
+
+var signals = map[string]os.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+}
+
+// ParseSignal converts signal name like SIGTERM or term to os.Signal.
+func ParseSignal(name string) (os.Signal, error) {
+	key := strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(key, "SIG") {
+		key = "SIG" + key
+	}
+
+	if sig, ok := signals[key]; ok {
+		return sig, nil
+	}
+	return nil, fmt.Errorf("unknown signal `%s`", name)
+}
+
+// OSSignals returns the signals which trigger graceful stop.
+func (c *Config) OSSignals() ([]os.Signal, error) {
+	oss := make([]os.Signal, 0, len(c.Signals))
+	for _, name := range c.Signals {
+		sig, err := ParseSignal(name)
+		if err != nil {
+			return nil, err
+		}
+		oss = append(oss, sig)
+	}
+	return oss, nil
+}
@@ -0,0 +1,131 @@
+package ioc
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const killTimeoutProcessEnv = "IOC_KILL_TIMEOUT_PROCESS"
+
+// hangingPlugin never returns from Stop.
+type hangingPlugin struct {
+	servicePlugin
+}
+
+func (p *hangingPlugin) Stop(context.Context) error {
+	select {}
+}
+
+func (p *hangingPlugin) Name() string { return "hanging" }
+
+func TestRunStopsOnSignal(t *testing.T) {
+	// keep the test process alive if the signal comes before Run subscribes to it
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGHUP)
+	defer signal.Stop(guard)
+
+	c := newTestContainer(t, "endure:\n  signals: [SIGHUP]\n  force_exit: false\n", &servicePlugin{})
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run() }()
+
+	waitFor(t, 5*time.Second, c.Ready)
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Info().State; got != StateStopped {
+				t.Errorf("State = %s, want %s", got, StateStopped)
+			}
+			return
+		case <-ticker.C:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after the signal")
+		}
+	}
+}
+
+// TestKillTimeoutProcess is the container process of TestKillTimeout.
+func TestKillTimeoutProcess(t *testing.T) {
+	if os.Getenv(killTimeoutProcessEnv) != "1" {
+		t.Skip("helper process of TestKillTimeout")
+	}
+
+	guard := make(chan os.Signal, 1)
+	signal.Notify(guard, syscall.SIGTERM)
+
+	c := newTestContainer(t, "endure:\n  grace_period: 1m\n  kill_timeout: 200ms\n  force_exit: false\n", &hangingPlugin{})
+
+	go func() {
+		waitFor(t, 5*time.Second, c.Ready)
+		fmt.Println("serving")
+	}()
+
+	_ = c.Run()
+
+	// the kill timeout should have exited with 1
+	os.Exit(0)
+}
+
+func TestKillTimeout(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestKillTimeoutProcess$") //nolint:gosec
+	cmd.Env = append(os.Environ(), killTimeoutProcessEnv+"=1")
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() && scanner.Text() != "serving" {
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	start := time.Now()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		// repeated until Run subscribes, force exit is disabled
+		_ = cmd.Process.Signal(syscall.SIGTERM)
+
+		select {
+		case err = <-exited:
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("process exited with %v, want exit status 1", err)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("process exited after %s, the grace period is 1m", elapsed)
+			}
+			return
+		case <-ticker.C:
+		case <-time.After(10 * time.Second):
+			_ = cmd.Process.Kill()
+			t.Fatal("process did not exit after the kill timeout")
+		}
+	}
+}
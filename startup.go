@@ -0,0 +1,152 @@
+package ioc
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const startupPollInterval = 50 * time.Millisecond
+
+// StartupTimeoutError is returned by Container.Init and Container.Serve when
+// a plugin did not return within its startup timeout.
+type StartupTimeoutError struct {
+	Phase   string
+	Plugin  string
+	Timeout time.Duration
+	// Stack is the goroutine dump of the stuck phase.
+	Stack []byte
+}
+
+func (e *StartupTimeoutError) Error() string {
+	if e.Plugin == "" {
+		return fmt.Sprintf("%s did not complete within %s", e.Phase, e.Timeout)
+	}
+	return fmt.Sprintf("plugin %s did not return from %s within %s", e.Plugin, e.Phase, e.Timeout)
+}
+
+// withStartupTimeout runs fn and watches which plugin the call is currently in,
+// failing as soon as a plugin exceeds its startup timeout.
+func (c *Container) withStartupTimeout(phase string, fn func() error) error {
+	if !c.conf.hasStartupTimeout() {
+		return fn()
+	}
+
+	idCh, done := make(chan uint64, 1), make(chan error, 1)
+
+	go func() {
+		idCh <- goroutineID()
+		done <- fn()
+	}()
+
+	id := <-idCh
+	prefixes := c.pluginPrefixes()
+
+	ticker := time.NewTicker(startupPollInterval)
+	defer ticker.Stop()
+
+	current, since := "", time.Now()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case now := <-ticker.C:
+			stack := goroutineStack(id)
+
+			if plugin := stuckPlugin(stack, prefixes); plugin != current {
+				current, since = plugin, now
+			}
+
+			timeout := c.conf.PluginStartupTimeout(current)
+			if timeout <= 0 || now.Sub(since) < timeout {
+				continue
+			}
+
+			err := &StartupTimeoutError{Phase: phase, Plugin: current, Timeout: timeout, Stack: stack}
+			c.log.Error(err.Error(), slog.String("stack", string(stack)))
+
+			return err
+		}
+	}
+}
+
+func (c *Config) hasStartupTimeout() bool {
+	if c.StartupTimeout > 0 {
+		return true
+	}
+	for _, p := range c.Plugins {
+		if p.StartupTimeout > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginPrefixes maps the stack frame prefixes of the plugins methods to the plugins names.
+func (c *Container) pluginPrefixes() map[string]string {
+	plugins := c.registered()
+
+	prefixes := make(map[string]string, len(plugins)*2)
+	for _, plugin := range plugins {
+		t := reflect.TypeOf(plugin)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		name := pluginName(plugin)
+		prefixes[fmt.Sprintf("%s.(*%s).", t.PkgPath(), t.Name())] = name
+		prefixes[fmt.Sprintf("%s.%s.", t.PkgPath(), t.Name())] = name
+	}
+	return prefixes
+}
+
+// stuckPlugin returns the outermost plugin found in the goroutine stack,
+// it's the one called by endure.
+func stuckPlugin(stack []byte, prefixes map[string]string) string {
+	var plugin string
+	for _, line := range strings.Split(string(stack), "\n") {
+		for prefix, name := range prefixes {
+			if strings.HasPrefix(line, prefix) {
+				plugin = name
+			}
+		}
+	}
+	return plugin
+}
+
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	// goroutine 18 [running]:
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	buf = buf[:bytes.IndexByte(buf, ' ')]
+
+	id, _ := strconv.ParseUint(string(buf), 10, 64)
+	return id
+}
+
+func goroutineStack(id uint64) []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := []byte(fmt.Sprintf("goroutine %d ", id))
+	for _, g := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(g, header) {
+			return g
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package ioc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rrErrs "github.com/roadrunner-server/errors"
+)
+
+// blockingPlugin blocks in Init until the channel is closed.
+type blockingPlugin struct {
+	release chan struct{}
+}
+
+func (p *blockingPlugin) Init() error {
+	<-p.release
+	return nil
+}
+
+func (p *blockingPlugin) Serve() chan error          { return make(chan error, 1) }
+func (p *blockingPlugin) Stop(context.Context) error { return nil }
+func (p *blockingPlugin) Name() string               { return "blocking" }
+
+// valuePlugin has the value receivers, so its stack frames have no (*T).
+type valuePlugin struct {
+	release chan struct{}
+}
+
+func (p valuePlugin) Init() error {
+	<-p.release
+	return nil
+}
+
+func (p valuePlugin) Serve() chan error          { return make(chan error, 1) }
+func (p valuePlugin) Stop(context.Context) error { return nil }
+func (p valuePlugin) Name() string               { return "value" }
+
+// unwrapOp returns the error wrapped with the container operation, rrErrs.Error has no Unwrap.
+func unwrapOp(err error) error {
+	var opErr *rrErrs.Error
+	if errors.As(err, &opErr) {
+		return opErr.Err
+	}
+	return err
+}
+
+func TestStartupTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		plugin func(release chan struct{}) interface{}
+		// stuck is the plugin expected in the error, empty if Init should succeed
+		stuck string
+	}{
+		{
+			name:   "pointer receiver stuck in Init",
+			config: "endure:\n  startup_timeout: 100ms\n",
+			plugin: func(release chan struct{}) interface{} { return &blockingPlugin{release: release} },
+			stuck:  "blocking",
+		},
+		{
+			name:   "value receiver stuck in Init",
+			config: "endure:\n  startup_timeout: 100ms\n",
+			plugin: func(release chan struct{}) interface{} { return &valuePlugin{release: release} },
+			stuck:  "value",
+		},
+		{
+			name:   "plugin timeout",
+			config: "endure:\n  plugins:\n    blocking:\n      startup_timeout: 100ms\n",
+			plugin: func(release chan struct{}) interface{} { return &blockingPlugin{release: release} },
+			stuck:  "blocking",
+		},
+		{
+			name:   "within the timeout",
+			config: "endure:\n  startup_timeout: 5s\n",
+			plugin: func(chan struct{}) interface{} { return &servicePlugin{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			defer close(release)
+
+			c := newTestContainer(t, tt.config, tt.plugin(release))
+
+			start := time.Now()
+			err := c.Init()
+
+			if tt.stuck == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			var terr *StartupTimeoutError
+			if !errors.As(unwrapOp(err), &terr) {
+				t.Fatalf("Init() error = %v, want StartupTimeoutError", err)
+			}
+			if terr.Plugin != tt.stuck || terr.Phase != "init" {
+				t.Errorf("StartupTimeoutError = %s/%s, want %s/init", terr.Plugin, terr.Phase, tt.stuck)
+			}
+			if len(terr.Stack) == 0 {
+				t.Error("StartupTimeoutError.Stack is empty")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Init() returned after %s", elapsed)
+			}
+
+			info := c.Info()
+			if info.State != StateFailed || info.LastError == nil {
+				t.Errorf("Info() = %s/%v, want failed with the error", info.State, info.LastError)
+			}
+		})
+	}
+}
+
+func TestStuckPlugin(t *testing.T) {
+	prefixes := map[string]string{
+		"github.com/x/app/http.(*Plugin).": "http",
+		"github.com/x/app/db.Plugin.":      "db",
+	}
+
+	tests := []struct {
+		name  string
+		stack string
+		want  string
+	}{
+		{
+			name:  "pointer receiver",
+			stack: "goroutine 7 [select]:\ngithub.com/x/app/http.(*Plugin).Init(0xc000010000)\n\t/app/http/plugin.go:10 +0x25\n",
+			want:  "http",
+		},
+		{
+			name:  "value receiver",
+			stack: "goroutine 7 [select]:\ngithub.com/x/app/db.Plugin.Init({0xc000010000})\n\t/app/db/plugin.go:10 +0x25\n",
+			want:  "db",
+		},
+		{
+			// db calls http, endure is in db
+			name: "outermost plugin",
+			stack: "goroutine 7 [select]:\ngithub.com/x/app/http.(*Plugin).Conn(0xc000010000)\n\t/app/http/plugin.go:20 +0x25\n" +
+				"github.com/x/app/db.Plugin.Init({0xc000010000})\n\t/app/db/plugin.go:10 +0x25\n",
+			want: "db",
+		},
+		{
+			name:  "no plugin",
+			stack: "goroutine 7 [select]:\nreflect.Value.call({0xc000010000})\n",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stuckPlugin([]byte(tt.stack), prefixes); got != tt.want {
+				t.Errorf("stuckPlugin() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}